@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Состояние автомата защиты
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // запросы идут, сбои считаются
+	BreakerOpen                         // запросы сразу завершаются ErrCircuitOpen
+	BreakerHalfOpen                     // пропускаются пробные запросы
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// Настройки автомата защиты. Сбоем считаются те же ошибки, что и
+// для повторов: таймауты, сетевые ошибки, 5xx и 429
+type BreakerSettings struct {
+	// FailureRatio - доля сбоев, при которой цепь размыкается; по умолчанию 0.5
+	FailureRatio float64
+	// MinRequests - сколько запросов нужно набрать, прежде чем оценивать
+	// долю сбоев; по умолчанию 10
+	MinRequests int
+	// Interval - окно подсчёта в замкнутом состоянии; 0 - счётчики
+	// сбрасываются только при смене состояния
+	Interval time.Duration
+	// CoolDown - сколько цепь остаётся разомкнутой; по умолчанию 5 с
+	CoolDown time.Duration
+	// HalfOpenRequests - число пробных запросов, которые должны пройти
+	// успешно, чтобы цепь замкнулась; по умолчанию 1
+	HalfOpenRequests int
+	// OnStateChange вызывается при каждой смене состояния, вне блокировки
+	OnStateChange func(from, to BreakerState)
+}
+
+// WithCircuitBreaker включает автомат защиты, общий для всех вызовов клиента
+func WithCircuitBreaker(settings BreakerSettings) ClientOption {
+	return func(o *clientOptions) { o.breaker = &settings }
+}
+
+// Автомат защиты; безопасен для одновременного использования
+type circuitBreaker struct {
+	settings BreakerSettings
+	clock    Clock
+
+	mu         sync.Mutex
+	state      BreakerState
+	generation uint64    // меняется со сменой состояния и окна, старые результаты отбрасываются
+	since      time.Time // начало состояния или окна подсчёта
+	requests   int
+	failures   int
+	successes  int // успешные пробные запросы
+}
+
+func newCircuitBreaker(settings BreakerSettings, clock Clock) *circuitBreaker {
+	if settings.FailureRatio <= 0 {
+		settings.FailureRatio = 0.5
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = 10
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = 5 * time.Second
+	}
+	if settings.HalfOpenRequests <= 0 {
+		settings.HalfOpenRequests = 1
+	}
+	return &circuitBreaker{settings: settings, clock: clock, since: clock.Now()}
+}
+
+// Итог вызова для автомата защиты и учёта состояния адресов
+const (
+	breakerSuccess = iota
+	breakerFailure
+	breakerIgnore // отмена вызывающим или ошибка запроса, а не сервера
+)
+
+// Разрешение на вызов; возвращает поколение, которое передаётся в done
+func (b *circuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	transition := b.advance(b.clock.Now())
+	generation := b.generation
+	err := error(nil)
+	switch {
+	case b.state == BreakerOpen,
+		b.state == BreakerHalfOpen && b.requests >= b.settings.HalfOpenRequests:
+		err = ErrCircuitOpen
+	default:
+		b.requests++
+	}
+	b.mu.Unlock()
+	b.notify(transition)
+	return generation, err
+}
+
+// Учёт итога вызова, разрешённого в поколении generation
+func (b *circuitBreaker) done(generation uint64, outcome int) {
+	b.mu.Lock()
+	now := b.clock.Now()
+	transition := b.advance(now)
+	if generation == b.generation {
+		switch {
+		case outcome == breakerIgnore:
+			// Слот пробного запроса освобождается, замкнутая цепь его не учитывает
+			b.requests--
+		case b.state == BreakerHalfOpen && outcome == breakerFailure:
+			transition = b.setState(BreakerOpen, now)
+		case b.state == BreakerHalfOpen:
+			if b.successes++; b.successes >= b.settings.HalfOpenRequests {
+				transition = b.setState(BreakerClosed, now)
+			}
+		case outcome == breakerFailure:
+			b.failures++
+			if b.requests >= b.settings.MinRequests &&
+				float64(b.failures) >= b.settings.FailureRatio*float64(b.requests) {
+				transition = b.setState(BreakerOpen, now)
+			}
+		}
+	}
+	b.mu.Unlock()
+	b.notify(transition)
+}
+
+// Смены состояния по времени: конец охлаждения и окна подсчёта
+func (b *circuitBreaker) advance(now time.Time) [2]BreakerState {
+	switch {
+	case b.state == BreakerOpen && !now.Before(b.since.Add(b.settings.CoolDown)):
+		return b.setState(BreakerHalfOpen, now)
+	case b.state == BreakerClosed && b.settings.Interval > 0 && !now.Before(b.since.Add(b.settings.Interval)):
+		b.reset(now)
+	}
+	return [2]BreakerState{b.state, b.state}
+}
+
+func (b *circuitBreaker) setState(state BreakerState, now time.Time) [2]BreakerState {
+	from := b.state
+	b.state = state
+	b.reset(now)
+	return [2]BreakerState{from, state}
+}
+
+func (b *circuitBreaker) reset(now time.Time) {
+	b.generation++
+	b.since = now
+	b.requests, b.failures, b.successes = 0, 0, 0
+}
+
+func (b *circuitBreaker) notify(transition [2]BreakerState) {
+	if transition[0] != transition[1] && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(transition[0], transition[1])
+	}
+}
+
+// Текущее состояние с учётом истёкшего охлаждения
+func (b *circuitBreaker) currentState() BreakerState {
+	b.mu.Lock()
+	transition := b.advance(b.clock.Now())
+	state := b.state
+	b.mu.Unlock()
+	b.notify(transition)
+	return state
+}
+
+// Сбой на стороне сервера или сети, в отличие от ошибок самого запроса
+// и отмены вызывающим
+func isBackendFailure(ctx context.Context, err error) bool {
+	var searchErr *SearchError
+	if ctx.Err() != nil || !errors.As(err, &searchErr) {
+		return false
+	}
+	switch {
+	case errors.Is(err, ErrTimeout), errors.Is(err, ErrRequestFailed) && searchErr.StatusCode == 0:
+		return true
+	}
+	return searchErr.StatusCode >= http.StatusInternalServerError || searchErr.StatusCode == http.StatusTooManyRequests
+}
+
+// Вызов через автомат защиты клиента, если он включён
+func (srv *SearchClient) withBreaker(ctx context.Context, call func() (*SearchResponse, error)) (*SearchResponse, error) {
+	if srv.breaker == nil {
+		return call()
+	}
+	generation, err := srv.breaker.allow()
+	if err != nil {
+		return nil, newSearchError(ErrCircuitOpen, nil, nil, "circuit breaker is open")
+	}
+	resp, err := call()
+	outcome := breakerSuccess
+	switch {
+	case isBackendFailure(ctx, err):
+		outcome = breakerFailure
+	case err != nil && ctx.Err() != nil:
+		outcome = breakerIgnore
+	}
+	srv.breaker.done(generation, outcome)
+	return resp, err
+}
+
+// BreakerState возвращает состояние автомата защиты; без него - BreakerClosed
+func (srv *SearchClient) BreakerState() BreakerState {
+	if srv.breaker == nil {
+		return BreakerClosed
+	}
+	return srv.breaker.currentState()
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Кэш ответов FindUsers. Ключ - URL сервера с нормализованными параметрами
+// запроса и хешем AccessToken, так что клиенты с разными токенами не видят
+// ответы друг друга даже в общем кэше. Реализация должна быть безопасна для
+// одновременного использования
+type ResponseCache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, entry *CachedResponse)
+}
+
+// Сохранённый ответ. До Expires он отдаётся без запроса, после -
+// перепроверяется на сервере по ETag
+type CachedResponse struct {
+	Response *SearchResponse
+	ETag     string
+	Expires  time.Time
+}
+
+// WithCache включает кэш ответов; ttl - сколько ответ считается свежим
+func WithCache(cache ResponseCache, ttl time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.cache = cache
+		o.cacheTTL = ttl
+	}
+}
+
+// Кэш ограниченного размера, вытесняющий давно не использованные ответы
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // от недавних к давним, значения - *lruEntry
+	entries  map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	entry *CachedResponse
+}
+
+// NewLRUCache создаёт кэш не более чем на capacity ответов, по умолчанию 256
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity <= 0 {
+		capacity = 256
+	}
+	return &LRUCache{capacity: capacity, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *LRUCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).entry, true
+}
+
+func (c *LRUCache) Set(key string, entry *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruEntry).entry = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key, entry})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len возвращает число сохранённых ответов
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Ключ кэша; токен хешируется, чтобы не оставлять его в открытом виде
+// во внешнем хранилище кэша
+func (srv *SearchClient) cacheKey(params url.Values) string {
+	token := sha256.Sum256([]byte(srv.AccessToken))
+	return srv.URL + "?" + params.Encode() + "\x00" + hex.EncodeToString(token[:])
+}
+
+// Свежий ответ из кэша
+func (srv *SearchClient) cachedResponse(key string) (*SearchResponse, bool) {
+	if srv.cache == nil {
+		return nil, false
+	}
+	entry, ok := srv.cache.Get(key)
+	if !ok || !srv.clockOrDefault().Now().Before(entry.Expires) {
+		return nil, false
+	}
+	return copyResponse(entry.Response), true
+}
+
+// Сохранение ответа; перепроверенный ответ снова свеж на ttl
+func (srv *SearchClient) storeResponse(key string, resp *SearchResponse, etag string) {
+	if srv.cache == nil {
+		return
+	}
+	srv.cache.Set(key, &CachedResponse{
+		Response: copyResponse(resp),
+		ETag:     etag,
+		Expires:  srv.clockOrDefault().Now().Add(srv.cacheTTL),
+	})
+}
+
+// Копия, чтобы вызывающий не мог изменить ответ в кэше
+func copyResponse(resp *SearchResponse) *SearchResponse {
+	copied := *resp
+	copied.Users = append([]User(nil), resp.Users...)
+	return &copied
+}
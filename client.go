@@ -1,14 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,20 +27,56 @@ var (
 )
 
 type User struct {
-	ID     int
-	Name   string
-	Age    int
-	About  string
-	Gender string
+	ID            int
+	Name          string
+	Age           int
+	About         string
+	Gender        string
+	GUID          string
+	IsActive      bool
+	Balance       int64 // в центах
+	Picture       string
+	EyeColor      string
+	Company       string
+	Email         string
+	Phone         string
+	Address       string
+	Registered    time.Time
+	FavoriteFruit string
+	Score         float64 // релевантность запросу при OrderField=Relevance
 }
 
 type SearchResponse struct {
 	Users    []User
 	NextPage bool
+	// версия и время загрузки снимка данных, из которого получен ответ
+	DatasetVersion  string
+	DatasetLoadedAt time.Time
+	// всего найдено пользователей и число страниц по Limit; -1 и 0,
+	// если сервер ответил без конверта
+	Total int
+	Pages int
+	// номер текущей страницы, с 1
+	Page int
+	// курсор следующей страницы для SearchRequest.Cursor; пустой на последней
+	NextCursor string
 }
 
+// Ответ сервера с метаданными страницы, см. HeaderSearchEnvelope
+type SearchEnvelope struct {
+	Users      []User `json:"users"`
+	Total      int    `json:"total"`
+	Offset     int    `json:"offset"`
+	Limit      int    `json:"limit"`
+	NextOffset *int   `json:"next_offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Тело ответа SearchServer с ошибкой
 type SearchErrorResponse struct {
-	Error string
+	Error   string // код ошибки, одна из констант Error*
+	Param   string `json:",omitempty"` // параметр запроса с ошибкой
+	Message string `json:",omitempty"` // описание для человека
 }
 
 const (
@@ -46,16 +84,63 @@ const (
 	OrderByAsIs = 0
 	OrderByDesc = -1
 
+	// коды ошибок в SearchErrorResponse.Error
 	ErrorBadOrderField = `OrderField invalid`
+	ErrorBadOrderBy    = `OrderBy invalid`
+	ErrorBadLimit      = `Limit invalid`
+	ErrorBadOffset     = `Offset invalid`
+	ErrorBadQuery      = `Query invalid`
+	ErrorBadCursor     = `Cursor invalid`
+	ErrorStaleCursor   = `Cursor stale`
+	ErrorBadParam      = `Param invalid`
+	ErrorUnauthorized  = `Unauthorized`
+	ErrorInternal      = `Internal error`
+	ErrorCanceled      = `Request canceled`
+
+	// заголовки ответа с версией и временем загрузки снимка данных
+	HeaderDatasetVersion  = "X-Dataset-Version"
+	HeaderDatasetLoadedAt = "X-Dataset-Loaded-At"
+	// заголовок запроса: "1" - вернуть SearchEnvelope вместо массива пользователей
+	HeaderSearchEnvelope = "X-Search-Envelope"
+	// заголовок запроса: "1" - последняя строка полной страницы запрошена
+	// только для определения NextPage и не входит в страницу курсора
+	HeaderSearchLookahead = "X-Search-Lookahead"
+	// заголовок ответа с курсором следующей страницы
+	HeaderNextCursor = "X-Next-Cursor"
 )
 
 type SearchRequest struct {
 	Limit      int
 	Offset     int    // Можно учесть после сортировки
-	Query      string // подстрока в 1 из полей
+	Query      string // слова из Name или About либо выражение вида name:boyd AND age:>30
+	QueryMode  string // QueryModeSubstring - искать Query как подстроку
+	Match      string // MatchExact, MatchCaseInsensitive или MatchNormalized
+	Locale     string // язык сортировки строковых полей, например "sv" или "cs-CZ"
 	OrderField string
 	//  1 по возрастанию, 0 как встретилось, -1 по убыванию
 	OrderBy int
+	// сортировка по нескольким полям; если задана, OrderField не используется
+	Sort []SortKey
+	// поля, которые нужно вернуть; пустой список - все поля
+	Fields []string
+	// курсор из SearchResponse.NextCursor; если задан, Offset не используется
+	Cursor string
+
+	// структурные фильтры, nil и пустые строки не ограничивают выборку
+	AgeMin        *int
+	AgeMax        *int
+	Gender        string
+	IsActive      *bool
+	EyeColor      string
+	Company       string
+	FavoriteFruit string
+}
+
+// Ключ сортировки: поле и направление OrderByAsc или OrderByDesc; другие
+// значения Order, в том числе нулевое, FindUsers отклоняет
+type SortKey struct {
+	Field string
+	Order int
 }
 
 type SearchClient struct {
@@ -63,75 +148,288 @@ type SearchClient struct {
 	AccessToken string
 	// урл внешней системы, куда идти
 	URL string
+
+	// настройки из NewSearchClient; нулевые значения - поведение по умолчанию
+	httpClient *http.Client
+	maxLimit   int
+	userAgent  string
+	headers    http.Header
+	retry      *RetryPolicy
+	clock      Clock
+	breaker    *circuitBreaker
+	cache      ResponseCache
+	cacheTTL   time.Duration
+	flights    *flightGroup
+	endpoints  *endpointSet
+	hedge      *hedger
 }
 
 // FindUsers отправляет запрос во внешнюю систему, которая непосредственно ищет пользователей
 func (srv *SearchClient) FindUsers(req SearchRequest) (*SearchResponse, error) {
+	return srv.FindUsersContext(context.Background(), req)
+}
+
+// FindUsersContext - FindUsers с отменой и сроком выполнения из ctx
+func (srv *SearchClient) FindUsersContext(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	req, params, err := srv.requestParams(req)
+	if err != nil {
+		return nil, err
+	}
+	// Свежий ответ из кэша не проходит ни через повторы, ни через автомат защиты
+	key := srv.cacheKey(params)
+	if resp, ok := srv.cachedResponse(key); ok {
+		return resp, nil
+	}
+	return srv.withDeduplication(ctx, params, func(ctx context.Context) (*SearchResponse, error) {
+		return srv.withRetry(ctx, func() (*SearchResponse, error) {
+			return srv.withBreaker(ctx, func() (*SearchResponse, error) {
+				return srv.findUsers(ctx, req, params, key)
+			})
+		})
+	})
+}
+
+// Проверка запроса и его параметры; Limit возвращённого запроса включает
+// лишнюю запись для определения следующей страницы
+func (srv *SearchClient) requestParams(req SearchRequest) (SearchRequest, url.Values, error) {
 
 	searcherParams := url.Values{}
 
 	if req.Limit < 0 {
-		return nil, fmt.Errorf("limit must be > 0")
+		return req, nil, newSearchError(ErrInvalidRequest, nil, nil, "limit must be > 0")
 	}
-	if req.Limit > 25 {
-		req.Limit = 25
+	if maxLimit := srv.pageLimit(); req.Limit > maxLimit {
+		req.Limit = maxLimit
 	}
 	if req.Offset < 0 {
-		return nil, fmt.Errorf("offset must be > 0")
+		return req, nil, newSearchError(ErrInvalidRequest, nil, nil, "offset must be > 0")
+	}
+	// Нулевой Order ключа легко оставить по ошибке, а направление из OrderBy
+	// у списка ключей было бы неочевидным
+	for _, key := range req.Sort {
+		if key.Order != OrderByAsc && key.Order != OrderByDesc {
+			return req, nil, newSearchError(ErrInvalidRequest, nil, nil, "order of sort key %s must be OrderByAsc or OrderByDesc", key.Field)
+		}
 	}
 
 	// нужно для получения следующей записи, на основе которой мы скажем - можно показать переключатель следующей страницы или нет
 	req.Limit++
 
 	searcherParams.Add("limit", strconv.Itoa(req.Limit))
-	searcherParams.Add("offset", strconv.Itoa(req.Offset))
+	if req.Cursor != "" {
+		searcherParams.Add("cursor", req.Cursor)
+	} else {
+		searcherParams.Add("offset", strconv.Itoa(req.Offset))
+	}
 	searcherParams.Add("query", req.Query)
-	searcherParams.Add("order_field", req.OrderField)
+	searcherParams.Add("order_field", encodeSortKeys(req))
 	searcherParams.Add("order_by", strconv.Itoa(req.OrderBy))
+	if req.QueryMode != "" {
+		searcherParams.Add("query_mode", req.QueryMode)
+	}
+	if req.Match != "" {
+		searcherParams.Add("match", req.Match)
+	}
+	if req.Locale != "" {
+		searcherParams.Add("locale", req.Locale)
+	}
+	if len(req.Fields) > 0 {
+		searcherParams.Add("fields", strings.Join(req.Fields, ","))
+	}
+	addFilterParams(searcherParams, req)
+	return req, searcherParams, nil
+}
 
-	searcherReq, _ := http.NewRequest("GET", srv.URL+"?"+searcherParams.Encode(), nil) //nolint:errcheck
-	searcherReq.Header.Add("AccessToken", srv.AccessToken)
+// Одна попытка запроса
+func (srv *SearchClient) findUsers(ctx context.Context, req SearchRequest, searcherParams url.Values, cacheKey string) (*SearchResponse, error) {
+	searcherReq, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"?"+searcherParams.Encode(), nil) //nolint:errcheck
+	for name, values := range srv.headers {
+		searcherReq.Header[name] = append([]string(nil), values...)
+	}
+	if srv.userAgent != "" {
+		searcherReq.Header.Set("User-Agent", srv.userAgent)
+	}
+	searcherReq.Header.Set("AccessToken", srv.AccessToken)
+	searcherReq.Header.Set(HeaderSearchEnvelope, "1")
+	searcherReq.Header.Set(HeaderSearchLookahead, "1")
+	// Устаревший ответ из кэша перепроверяется: сервер ответит 304, если он не изменился
+	var cached *CachedResponse
+	if srv.cache != nil {
+		if entry, ok := srv.cache.Get(cacheKey); ok && entry.ETag != "" {
+			cached = entry
+			searcherReq.Header.Set("If-None-Match", entry.ETag)
+		}
+	}
 
-	resp, err := client.Do(searcherReq)
+	resp, err := srv.send(ctx, searcherReq)
 	if err != nil {
-		if err, ok := err.(net.Error); ok && err.Timeout() {
-			return nil, fmt.Errorf("timeout for %s", searcherParams.Encode())
-		}
-		return nil, fmt.Errorf("unknown error %s", err)
+		return nil, requestError(ctx, searcherParams, err)
 	}
 	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body) //nolint:errcheck
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, requestError(ctx, searcherParams, err)
+	}
+
+	// Код и параметр ошибки, если сервер прислал их в теле ответа
+	errResp := SearchErrorResponse{}
+	if resp.StatusCode != http.StatusOK {
+		json.Unmarshal(body, &errResp) //nolint:errcheck
+	}
+	fail := func(kind, cause error, format string, args ...interface{}) *SearchError {
+		e := newSearchError(kind, searcherParams, cause, format, args...)
+		e.StatusCode = resp.StatusCode
+		e.Code, e.Param = errResp.Error, errResp.Param
+		e.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), srv.clockOrDefault().Now())
+		return e
+	}
 
 	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		if cached == nil {
+			return nil, fail(ErrRequestFailed, nil, "unexpected status %d: %s", resp.StatusCode, errResp.Message)
+		}
+		srv.storeResponse(cacheKey, cached.Response, cached.ETag)
+		return copyResponse(cached.Response), nil
 	case http.StatusUnauthorized:
-		return nil, fmt.Errorf("bad AccessToken")
+		return nil, fail(ErrBadAccessToken, nil, "bad AccessToken")
 	case http.StatusInternalServerError:
-		return nil, fmt.Errorf("SearchServer fatal error")
+		return nil, fail(ErrServerFatal, nil, "SearchServer fatal error")
 	case http.StatusBadRequest:
-		errResp := SearchErrorResponse{}
-		err = json.Unmarshal(body, &errResp)
-		if err != nil {
-			return nil, fmt.Errorf("cant unpack error json: %s", err)
+		if err = json.Unmarshal(body, &errResp); err != nil {
+			return nil, fail(ErrBadResponse, err, "cant unpack error json: %s", err)
 		}
 		if errResp.Error == ErrorBadOrderField {
-			return nil, fmt.Errorf("OrderFeld %s invalid", req.OrderField)
+			return nil, fail(ErrInvalidOrderField, nil, "OrderFeld %s invalid", encodeSortKeys(req))
+		}
+		// Описание понятнее кода, но старые серверы присылают только код
+		detail := errResp.Message
+		if detail == "" {
+			detail = errResp.Error
 		}
-		return nil, fmt.Errorf("unknown bad request error: %s", errResp.Error)
+		return nil, fail(ErrBadRequest, nil, "unknown bad request error: %s", detail)
+	default:
+		return nil, fail(ErrRequestFailed, nil, "unexpected status %d: %s", resp.StatusCode, errResp.Message)
 	}
 
-	data := []User{}
-	err = json.Unmarshal(body, &data)
+	// Сервер без поддержки конверта отвечает массивом пользователей
+	envelope := SearchEnvelope{Total: -1}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '{' {
+		err = json.Unmarshal(body, &envelope)
+	} else {
+		err = json.Unmarshal(body, &envelope.Users)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("cant unpack result json: %s", err)
+		return nil, fail(ErrBadResponse, err, "cant unpack result json: %s", err)
+	}
+	data := envelope.Users
+	if data == nil {
+		data = []User{}
 	}
 
-	result := SearchResponse{}
+	result := SearchResponse{
+		DatasetVersion: resp.Header.Get(HeaderDatasetVersion),
+		Total:          envelope.Total,
+		NextCursor:     resp.Header.Get(HeaderNextCursor),
+	}
+	result.DatasetLoadedAt, _ = time.Parse(time.RFC3339, resp.Header.Get(HeaderDatasetLoadedAt)) //nolint:errcheck
 	if len(data) == req.Limit {
 		result.NextPage = true
 		result.Users = data[0 : len(data)-1]
 	} else {
 		result.Users = data[0:]
 	}
+	// При переходе по курсору смещение страницы знает только сервер
+	offset := req.Offset
+	if envelope.Total >= 0 {
+		offset = envelope.Offset
+	}
+	result.Page, result.Pages = pageNumbers(result.Total, offset, req.Limit-1)
+	srv.storeResponse(cacheKey, &result, resp.Header.Get("ETag"))
 
 	return &result, err
 }
+
+// Ошибка отправки запроса или чтения ответа. Отмена и истечение срока ctx
+// различаются и доступны через errors.Is, таймаут самого клиента - прежний текст
+func requestError(ctx context.Context, params url.Values, err error) error {
+	switch ctxErr := ctx.Err(); {
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		return newSearchError(ErrTimeout, params, ctxErr, "deadline exceeded for %s: %s", params.Encode(), ctxErr)
+	case errors.Is(ctxErr, context.Canceled):
+		return newSearchError(ErrRequestFailed, params, ctxErr, "request canceled for %s: %s", params.Encode(), ctxErr)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return newSearchError(ErrTimeout, params, err, "timeout for %s", params.Encode())
+	}
+	return newSearchError(ErrRequestFailed, params, err, "unknown error %s", err)
+}
+
+// FindNextUsers запрашивает страницу, следующую за prev, по её курсору
+func (srv *SearchClient) FindNextUsers(req SearchRequest, prev *SearchResponse) (*SearchResponse, error) {
+	return srv.FindNextUsersContext(context.Background(), req, prev)
+}
+
+func (srv *SearchClient) FindNextUsersContext(ctx context.Context, req SearchRequest, prev *SearchResponse) (*SearchResponse, error) {
+	if prev.NextCursor == "" {
+		return nil, ErrNoNextPage
+	}
+	req.Cursor = prev.NextCursor
+	req.Offset = 0
+	return srv.FindUsersContext(ctx, req)
+}
+
+// Номер страницы с 1 и число страниц; без общего числа или при нулевом
+// лимите число страниц неизвестно
+func pageNumbers(total, offset, limit int) (page, pages int) {
+	if limit == 0 {
+		return 1, 0
+	}
+	page = offset/limit + 1
+	if total >= 0 {
+		pages = (total + limit - 1) / limit
+	}
+	return page, pages
+}
+
+// Значение order_field: одно поле или список вида "Age:desc,Name:asc"
+func encodeSortKeys(req SearchRequest) string {
+	if len(req.Sort) == 0 {
+		return req.OrderField
+	}
+	keys := make([]string, len(req.Sort))
+	for i, key := range req.Sort {
+		direction := "asc"
+		if key.Order == OrderByDesc {
+			direction = "desc"
+		}
+		keys[i] = key.Field + ":" + direction
+	}
+	return strings.Join(keys, ",")
+}
+
+// Добавление в запрос только заданных фильтров
+func addFilterParams(params url.Values, req SearchRequest) {
+	if req.AgeMin != nil {
+		params.Add("age_min", strconv.Itoa(*req.AgeMin))
+	}
+	if req.AgeMax != nil {
+		params.Add("age_max", strconv.Itoa(*req.AgeMax))
+	}
+	if req.IsActive != nil {
+		params.Add("is_active", strconv.FormatBool(*req.IsActive))
+	}
+	for name, value := range map[string]string{
+		"gender":         req.Gender,
+		"eye_color":      req.EyeColor,
+		"company":        req.Company,
+		"favorite_fruit": req.FavoriteFruit,
+	} {
+		if value != "" {
+			params.Add(name, value)
+		}
+	}
+}
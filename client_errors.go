@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Виды ошибок FindUsers для errors.Is. Сами ошибки - *SearchError,
+// их текст совпадает с прежним
+var (
+	ErrInvalidRequest    = errors.New("invalid search request")
+	ErrBadAccessToken    = errors.New("bad AccessToken")
+	ErrServerFatal       = errors.New("SearchServer fatal error")
+	ErrInvalidOrderField = errors.New("invalid order field")
+	ErrBadRequest        = errors.New("bad request")
+	ErrBadResponse       = errors.New("malformed response")
+	ErrTimeout           = errors.New("search request timeout")
+	ErrRequestFailed     = errors.New("search request failed")
+	ErrNoNextPage        = errors.New("no next page")
+	// автомат защиты разомкнут, запрос не отправлялся
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+)
+
+// Ошибка запроса к SearchServer
+type SearchError struct {
+	Kind       error      // один из Err* выше
+	StatusCode int        // HTTP-статус ответа; 0, если ответа не было
+	Code       string     // код ошибки из тела ответа сервера, если он его прислал
+	Param      string     // параметр запроса, в котором сервер нашёл ошибку
+	Params     url.Values // параметры запроса
+	Err        error      // исходная ошибка: net.Error, ошибка ctx или разбора JSON
+	// пауза, которую сервер попросил выдержать перед повтором (Retry-After)
+	RetryAfter time.Duration
+	msg        string
+}
+
+func newSearchError(kind error, params url.Values, cause error, format string, args ...interface{}) *SearchError {
+	return &SearchError{Kind: kind, Params: params, Err: cause, msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *SearchError) Error() string {
+	return e.msg
+}
+
+// Unwrap даёт errors.Is и errors.As доступ и к виду ошибки, и к причине
+func (e *SearchError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// Размер страницы, больше которого клиент не запрашивает по умолчанию
+const defaultMaxLimit = 25
+
+// Настройка SearchClient при создании через NewSearchClient
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	httpClient *http.Client
+	transport  http.RoundTripper
+	timeout    time.Duration
+	maxLimit   int
+	userAgent  string
+	headers    http.Header
+	retry      *RetryPolicy
+	clock      Clock
+	breaker    *BreakerSettings
+	cache      ResponseCache
+	cacheTTL   time.Duration
+	dedupe     bool
+
+	endpoints      []string
+	endpointPolicy EndpointPolicy
+	hedge          *HedgePolicy
+}
+
+// WithHTTPClient задаёт HTTP-клиент; Timeout и Transport, если заданы,
+// применяются к его копии
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(o *clientOptions) { o.httpClient = c }
+}
+
+// WithTransport подменяет транспорт, например, чтобы в тестах обойтись без сервера
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(o *clientOptions) { o.transport = rt }
+}
+
+// WithTimeout ограничивает время одного запроса, по умолчанию секунда
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) { o.timeout = d }
+}
+
+// WithMaxLimit задаёт наибольший размер страницы, который разрешает сервер
+func WithMaxLimit(n int) ClientOption {
+	return func(o *clientOptions) { o.maxLimit = n }
+}
+
+// WithUserAgent задаёт заголовок User-Agent запросов
+func WithUserAgent(ua string) ClientOption {
+	return func(o *clientOptions) { o.userAgent = ua }
+}
+
+// WithBaseHeaders добавляет заголовки к каждому запросу. Служебные
+// заголовки клиента, включая AccessToken, ими не переопределяются
+func WithBaseHeaders(h http.Header) ClientOption {
+	return func(o *clientOptions) { o.headers = h.Clone() }
+}
+
+// NewSearchClient создаёт клиент; без опций он ведёт себя так же, как
+// &SearchClient{AccessToken: accessToken, URL: url}
+func NewSearchClient(accessToken, url string, opts ...ClientOption) *SearchClient {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	httpClient := client
+	if o.httpClient != nil {
+		httpClient = o.httpClient
+	}
+	if o.timeout > 0 || o.transport != nil {
+		copied := *httpClient
+		if o.timeout > 0 {
+			copied.Timeout = o.timeout
+		}
+		if o.transport != nil {
+			copied.Transport = o.transport
+		}
+		httpClient = &copied
+	}
+
+	srv := &SearchClient{
+		AccessToken: accessToken,
+		URL:         url,
+		httpClient:  httpClient,
+		maxLimit:    o.maxLimit,
+		userAgent:   o.userAgent,
+		headers:     o.headers,
+		retry:       o.retry,
+		clock:       o.clock,
+		cache:       o.cache,
+		cacheTTL:    o.cacheTTL,
+	}
+	if len(o.endpoints) > 0 {
+		srv.endpoints = newEndpointSet(o.endpoints, o.endpointPolicy, srv.clockOrDefault())
+	}
+	if o.hedge != nil {
+		srv.hedge = newHedger(*o.hedge)
+	}
+	if o.dedupe {
+		srv.flights = &flightGroup{}
+	}
+	if o.breaker != nil {
+		srv.breaker = newCircuitBreaker(*o.breaker, srv.clockOrDefault())
+	}
+	return srv
+}
+
+// HTTP-клиент запросов; у созданного литералом SearchClient - общий client
+func (srv *SearchClient) doer() *http.Client {
+	if srv.httpClient == nil {
+		return client
+	}
+	return srv.httpClient
+}
+
+// Наибольший размер страницы
+func (srv *SearchClient) pageLimit() int {
+	if srv.maxLimit <= 0 {
+		return defaultMaxLimit
+	}
+	return srv.maxLimit
+}
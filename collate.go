@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Правила сортировки строк языка поверх общего порядка collationKey.
+// Ключ правила - буква или сочетание букв после свёртки регистра в
+// составной форме, значение - её место в ключе сопоставления
+type collation map[string]string
+
+// Буквы letters по порядку идут отдельными буквами сразу после base, а не
+// рядом с base, как в общем порядке. Символ utf8.MaxRune больше любого
+// другого в ключе, поэтому "ña" встаёт после "nz", но перед "o"
+func (c collation) after(base string, letters ...string) collation {
+	for n, letter := range letters {
+		c[letter] = base + string(utf8.MaxRune) + string(rune('0'+n))
+	}
+	return c
+}
+
+// Буквы like язык при сортировке не отличает от letter
+func (c collation) same(letter string, like ...string) collation {
+	for _, l := range like {
+		c[l] = c[letter]
+	}
+	return c
+}
+
+// Языки сортировки. nil - язык, для которого подходит общий порядок
+var collations = map[string]collation{
+	"":   nil,
+	"en": nil,
+	"de": nil,
+	"fr": nil,
+	"it": nil,
+	"nl": nil,
+	"pt": nil,
+	"sv": collation{}.after("z", "å", "ä", "ö").same("ä", "æ").same("ö", "ø"),
+	"fi": collation{}.after("z", "å", "ä", "ö").same("ä", "æ").same("ö", "ø"),
+	"da": collation{}.after("z", "æ", "ø", "å").same("æ", "ä").same("ø", "ö"),
+	"nb": collation{}.after("z", "æ", "ø", "å").same("æ", "ä").same("ø", "ö"),
+	"es": collation{}.after("n", "ñ"),
+	"cs": collation{}.after("c", "č").after("h", "ch").after("r", "ř").after("s", "š").after("z", "ž"),
+	"pl": collation{}.after("a", "ą").after("c", "ć").after("e", "ę").after("l", "ł").after("n", "ń").
+		after("o", "ó").after("s", "ś").after("z", "ź", "ż"),
+	"ru": collation{}.after("и", "й"),
+	"uk": collation{}.after("г", "ґ").after("е", "є").after("и", "і", "ї", "й"),
+}
+
+// Язык сортировки из значения вида "sv", "sv-SE" или "sv_SE"
+func parseLocale(value string) (string, bool) {
+	lang, _, _ := strings.Cut(strings.ReplaceAll(strings.ToLower(value), "_", "-"), "-")
+	_, ok := collations[lang]
+	return lang, ok
+}
+
+// Ключ сопоставления строки по правилам языка; без правил - collationKey
+func (c collation) key(s string) string {
+	if c == nil {
+		return collationKey(s)
+	}
+	s = foldCase(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for len(s) > 0 {
+		rule, size := c.match(s)
+		if size > 0 {
+			b.WriteString(rule)
+		} else {
+			var r rune
+			r, size = utf8.DecodeRuneInString(s)
+			writeNormalizedRune(&b, r)
+		}
+		s = s[size:]
+	}
+	return b.String()
+}
+
+// Правило для начала s: сначала для пары символов, затем для одного
+func (c collation) match(s string) (string, int) {
+	_, first := utf8.DecodeRuneInString(s)
+	if _, second := utf8.DecodeRuneInString(s[first:]); second > 0 {
+		if rule, ok := c[s[:first+second]]; ok {
+			return rule, first + second
+		}
+	}
+	if rule, ok := c[s[:first]]; ok {
+		return rule, first
+	}
+	return "", 0
+}
@@ -1,19 +1,40 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/iotest"
 	"time"
 )
 
+// Хранилище поверх dataset.xml и обработчик, общие для тестов; обработчик
+// один, чтобы курсоры одного теста подписывались одним ключом
+var (
+	testStore   = NewXMLFileStore("dataset.xml")
+	testHandler = NewSearchHandler(testStore, SearchHandlerOptions{})
+)
+
+func SearchServer(w http.ResponseWriter, r *http.Request) {
+	testHandler.ServeHTTP(w, r)
+}
+
 func TestFindUsersLimitOffset(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
 	defer ts.Close()
@@ -238,45 +259,29 @@ func TestInvalidOffset(t *testing.T) {
 }
 
 func TestLoadDataFileCloseSuccess(t *testing.T) {
-	originalDatasetFilePath := datasetFilePath
-	datasetFilePath = "dataset.xml"
-
-	err := loadData()
+	err := NewXMLFileStore("dataset.xml").Load()
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-
-	datasetFilePath = originalDatasetFilePath
 }
-
 func TestLoadDataSuccess(t *testing.T) {
-	originalDatasetFilePath := datasetFilePath
-	datasetFilePath = "dataset.xml"
+	store := NewXMLFileStore("dataset.xml")
 
-	err := loadData()
+	err := store.Load()
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	if len(users) == 0 {
+	if ds := store.current.Load(); ds == nil || len(ds.users) == 0 {
 		t.Errorf("expected users to be loaded, got none")
 	}
-
-	datasetFilePath = originalDatasetFilePath
 }
-
 func TestLoadData_FileOpenError(t *testing.T) {
-	originalPath := datasetFilePath
-	defer func() { datasetFilePath = originalPath }()
-
-	datasetFilePath = "non_existent_file.xml"
-
-	err := loadData()
+	err := NewXMLFileStore("non_existent_file.xml").Load()
 	if err == nil {
 		t.Errorf("Expected error when opening non-existent file, but got nil")
 	}
 }
-
 func TestLoadData_XMLDecodeError(t *testing.T) {
 	tmpFile, err := os.CreateTemp("", "invalid_xml*.xml")
 	if err != nil {
@@ -289,11 +294,7 @@ func TestLoadData_XMLDecodeError(t *testing.T) {
 	}
 	tmpFile.Close()
 
-	originalPath := datasetFilePath
-	defer func() { datasetFilePath = originalPath }()
-	datasetFilePath = tmpFile.Name()
-
-	err = loadData()
+	err = NewXMLFileStore(tmpFile.Name()).Load()
 	if err == nil {
 		t.Errorf("Expected XML decode error, but got nil")
 	}
@@ -308,8 +309,8 @@ func TestHandleError(t *testing.T) {
 		t.Errorf("Expected status code %v, but got %v", http.StatusBadRequest, status)
 	}
 
-	expected := "test error\n"
-	if rr.Body.String() != expected {
+	expected := `{"Error":"Param invalid","Message":"test error"}` + "\n"
+	if rr.Body.String() != expected || rr.Header().Get("Content-Type") != "application/json" {
 		t.Errorf("Expected body %v, but got %v", expected, rr.Body.String())
 	}
 }
@@ -343,7 +344,7 @@ func TestValidateIntParam_InvalidValue(t *testing.T) {
 
 func TestValidateParams_InvalidLimit(t *testing.T) {
 	req := httptest.NewRequest("GET", "/?limit=invalid", nil)
-	_, _, _, _, err := validateParams(req)
+	_, err := validateParams(req)
 	if err == nil {
 		t.Errorf("Expected error for invalid limit, but got nil")
 	}
@@ -351,7 +352,7 @@ func TestValidateParams_InvalidLimit(t *testing.T) {
 
 func TestValidateParams_InvalidOffset(t *testing.T) {
 	req := httptest.NewRequest("GET", "/?offset=invalid", nil)
-	_, _, _, _, err := validateParams(req)
+	_, err := validateParams(req)
 	if err == nil {
 		t.Errorf("Expected error for invalid offset, but got nil")
 	}
@@ -359,7 +360,7 @@ func TestValidateParams_InvalidOffset(t *testing.T) {
 
 func TestValidateParams_InvalidOrderField(t *testing.T) {
 	req := httptest.NewRequest("GET", "/?order_field=InvalidField", nil)
-	_, _, _, _, err := validateParams(req)
+	_, err := validateParams(req)
 	if err == nil {
 		t.Errorf("Expected error for invalid order_field, but got nil")
 	}
@@ -367,26 +368,26 @@ func TestValidateParams_InvalidOrderField(t *testing.T) {
 
 func TestValidateParams_ValidParams(t *testing.T) {
 	req := httptest.NewRequest("GET", "/?limit=10&offset=0&order_field=Name&order_by=1", nil)
-	limit, offset, orderField, orderBy, err := validateParams(req)
+	params, err := validateParams(req)
 
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
-	if limit != 10 {
-		t.Errorf("Expected limit 10, but got %v", limit)
+	if params.limit != 10 {
+		t.Errorf("Expected limit 10, but got %v", params.limit)
 	}
 
-	if offset != 0 {
-		t.Errorf("Expected offset 0, but got %v", offset)
+	if params.offset != 0 {
+		t.Errorf("Expected offset 0, but got %v", params.offset)
 	}
 
-	if orderField != "Name" {
-		t.Errorf("Expected order_field 'Name', but got %v", orderField)
+	if params.query.OrderField != "Name" {
+		t.Errorf("Expected order_field 'Name', but got %v", params.query.OrderField)
 	}
 
-	if orderBy != 1 {
-		t.Errorf("Expected order_by 1, but got %v", orderBy)
+	if params.query.OrderBy != 1 {
+		t.Errorf("Expected order_by 1, but got %v", params.query.OrderBy)
 	}
 }
 
@@ -397,7 +398,7 @@ func TestFilterAndSortUsers_NameFilter(t *testing.T) {
 		{ID: 3, Name: "Charlie", Age: 35, About: "Teacher"},
 	}
 
-	filtered := filterAndSortUsers(users, "Bob", "Name", 0)
+	filtered, _ := filterAndSortUsers(context.Background(), newDataset(users, time.Now()), SearchQuery{Query: "Bob", OrderField: "Name", OrderBy: 0})
 
 	if len(filtered) != 1 || filtered[0].Name != "Bob" {
 		t.Errorf("Expected 1 user named 'Bob', but got %v", filtered)
@@ -411,7 +412,7 @@ func TestFilterAndSortUsers_AboutFilter(t *testing.T) {
 		{ID: 3, Name: "Charlie", Age: 35, About: "Engineer"},
 	}
 
-	filtered := filterAndSortUsers(users, "Engineer", "Name", 0)
+	filtered, _ := filterAndSortUsers(context.Background(), newDataset(users, time.Now()), SearchQuery{Query: "Engineer", OrderField: "Name", OrderBy: 0})
 
 	if len(filtered) != 2 {
 		t.Errorf("Expected 2 users with 'Engineer' in About, but got %v", len(filtered))
@@ -425,13 +426,13 @@ func TestFilterAndSortUsers_SortByID(t *testing.T) {
 		{ID: 3, Name: "Charlie", Age: 35},
 	}
 
-	sorted := filterAndSortUsers(users, "", "Id", 1)
+	sorted, _ := filterAndSortUsers(context.Background(), newDataset(users, time.Now()), SearchQuery{OrderField: "Id", OrderBy: 1})
 
 	if sorted[0].ID != 1 || sorted[1].ID != 2 || sorted[2].ID != 3 {
 		t.Errorf("Expected users sorted by ID ascending, but got %v", sorted)
 	}
 
-	sorted = filterAndSortUsers(users, "", "Id", -1)
+	sorted, _ = filterAndSortUsers(context.Background(), newDataset(users, time.Now()), SearchQuery{OrderField: "Id", OrderBy: -1})
 
 	if sorted[0].ID != 3 || sorted[1].ID != 2 || sorted[2].ID != 1 {
 		t.Errorf("Expected users sorted by ID descending, but got %v", sorted)
@@ -445,13 +446,13 @@ func TestFilterAndSortUsers_SortByAge(t *testing.T) {
 		{ID: 3, Name: "Charlie", Age: 35},
 	}
 
-	sorted := filterAndSortUsers(users, "", "Age", 1)
+	sorted, _ := filterAndSortUsers(context.Background(), newDataset(users, time.Now()), SearchQuery{OrderField: "Age", OrderBy: 1})
 
 	if sorted[0].Age != 25 || sorted[1].Age != 30 || sorted[2].Age != 35 {
 		t.Errorf("Expected users sorted by Age ascending, but got %v", sorted)
 	}
 
-	sorted = filterAndSortUsers(users, "", "Age", -1)
+	sorted, _ = filterAndSortUsers(context.Background(), newDataset(users, time.Now()), SearchQuery{OrderField: "Age", OrderBy: -1})
 
 	if sorted[0].Age != 35 || sorted[1].Age != 30 || sorted[2].Age != 25 {
 		t.Errorf("Expected users sorted by Age descending, but got %v", sorted)
@@ -481,16 +482,13 @@ func TestSearchServer_InvalidLimit(t *testing.T) {
 	}
 }
 func TestSearchServer_LoadDataError(t *testing.T) {
-	originalPath := datasetFilePath
-	defer func() { datasetFilePath = originalPath }()
-
-	datasetFilePath = "non_existent_file.xml"
+	handler := NewSearchHandler(NewXMLFileStore("non_existent_file.xml"), SearchHandlerOptions{})
 
 	req := httptest.NewRequest("GET", "/?limit=10", nil)
 	req.Header.Set("AccessToken", "valid_token")
 	rr := httptest.NewRecorder()
 
-	SearchServer(rr, req)
+	handler.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusInternalServerError {
 		t.Errorf("Expected status code %v, but got %v", http.StatusInternalServerError, rr.Code)
@@ -505,6 +503,64 @@ func TestSearchServer_LoadDataError(t *testing.T) {
 		t.Errorf("Expected body to match pattern %v, but got %v", expectedPattern, rr.Body.String())
 	}
 }
+func TestSearchServer_DatasetLoadedOnce(t *testing.T) {
+	path := copyDataset(t)
+	store := NewXMLFileStore(path)
+	handler := NewSearchHandler(store, SearchHandlerOptions{})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/?limit=1", nil)
+		req.Header.Set("AccessToken", "valid_token")
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status %v, got %v", http.StatusOK, rr.Code)
+		}
+
+		// После первого запроса файл больше не нужен
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			t.Fatalf("Failed to remove dataset: %v", err)
+		}
+	}
+}
+func TestSearchServer_ConcurrentRequests(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	queries := []SearchRequest{
+		{Limit: 5, OrderField: "Age", OrderBy: OrderByDesc},
+		{Limit: 5, Query: "on", OrderField: "Id", OrderBy: OrderByAsc},
+		{Limit: 25, OrderField: "Name", OrderBy: OrderByAsc},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 30)
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(n int, req SearchRequest) {
+			defer wg.Done()
+			// Часть горутин параллельно подменяет снимок
+			if n%5 == 0 {
+				if err := testStore.Load(); err != nil {
+					errs <- err
+					return
+				}
+			}
+			client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+			if _, err := client.FindUsers(req); err != nil {
+				errs <- err
+			}
+		}(i, queries[i%len(queries)])
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
 
 func TestSearchServer_WriteJSONError(t *testing.T) {
 	// Создаем тестовый запрос
@@ -628,3 +684,2688 @@ func TestFindUsers_UnknownBadRequestError(t *testing.T) {
 		t.Errorf("expected error 'unknown bad request error: Some unknown error', got %v", err)
 	}
 }
+
+// Копия dataset.xml во временном каталоге теста
+func copyDataset(t *testing.T) string {
+	t.Helper()
+	original, err := os.ReadFile("dataset.xml")
+	if err != nil {
+		t.Fatalf("Failed to read dataset: %v", err)
+	}
+	path := t.TempDir() + "/dataset.xml"
+	writeDatasetFile(t, path, string(original))
+	return path
+}
+
+func writeDatasetFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write dataset file: %v", err)
+	}
+}
+
+const twoRowsXML = `<root>
+<row><id>1</id><first_name>Ann</first_name><last_name>Lee</last_name><age>20</age></row>
+<row><id>2</id><first_name>Bob</first_name><last_name>Ray</last_name><age>30</age></row>
+</root>`
+
+func TestWatchDataset_ReloadAndKeepLastGood(t *testing.T) {
+	path := copyDataset(t)
+	store := NewXMLFileStore(path)
+	if err := store.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := store.current.Load()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.Watch(ctx, 5*time.Millisecond, func(err error) { errs <- err })
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor := func(cond func() bool) bool {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if cond() {
+				return true
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return false
+	}
+
+	writeDatasetFile(t, path, twoRowsXML)
+	if !waitFor(func() bool { return store.current.Load().version != first.version }) {
+		t.Fatalf("Expected dataset to be reloaded after file change")
+	}
+	reloaded := store.current.Load()
+	if len(reloaded.users) != 2 || reloaded.users[1].Name != "Bob Ray" {
+		t.Errorf("Expected 2 users from the new file, got %v", reloaded.users)
+	}
+
+	writeDatasetFile(t, path, "<root><row><id>broken")
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "failed to decode XML") {
+			t.Errorf("Expected decode error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected reload error for broken file")
+	}
+	if store.current.Load() != reloaded {
+		t.Errorf("Expected last good snapshot to be kept after failed reload")
+	}
+}
+
+func TestReloadIfChanged_Unchanged(t *testing.T) {
+	store := NewXMLFileStore("dataset.xml")
+	if err := store.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded := store.current.Load()
+
+	if _, err := store.reloadIfChanged(fileStamp{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.current.Load() != loaded {
+		t.Errorf("Expected unchanged file not to be reloaded")
+	}
+
+	if _, err := NewXMLFileStore("non_existent_file.xml").reloadIfChanged(fileStamp{}); err == nil {
+		t.Errorf("Expected error for missing file, got nil")
+	}
+
+	// Версия файла, которую не удалось загрузить, не разбирается повторно
+	path := t.TempDir() + "/dataset.xml"
+	writeDatasetFile(t, path, `<root></root>`)
+	broken := NewXMLFileStore(path)
+	failed, err := broken.reloadIfChanged(fileStamp{})
+	if err == nil {
+		t.Fatalf("Expected error for invalid file, got nil")
+	}
+	if stamp, err := broken.reloadIfChanged(failed); err != nil || !stamp.equal(failed) {
+		t.Errorf("Expected failed file version to be skipped, got %v", err)
+	}
+}
+func TestLoadDataset_Validation(t *testing.T) {
+	cases := map[string]string{
+		"no rows":        `<root></root>`,
+		"duplicate id 1": `<root><row><id>1</id></row><row><id>1</id></row></root>`,
+	}
+	for expected, content := range cases {
+		path := t.TempDir() + "/dataset.xml"
+		writeDatasetFile(t, path, content)
+
+		_, err := loadDataset(path)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestFindUsers_DatasetVersion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	ds, err := testStore.snapshot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	resp, err := client.FindUsers(SearchRequest{Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if resp.DatasetVersion != ds.version {
+		t.Errorf("Expected dataset version %q, got %q", ds.version, resp.DatasetVersion)
+	}
+	if !resp.DatasetLoadedAt.Equal(ds.loadedAt.Truncate(time.Second)) {
+		t.Errorf("Expected dataset load time %v, got %v", ds.loadedAt, resp.DatasetLoadedAt)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore([]UserServer{
+		{ID: 2, Name: "Bob Ray", Age: 30},
+		{ID: 1, Name: "Ann Lee", Age: 20},
+	})
+	ctx := context.Background()
+
+	all, err := store.List(ctx)
+	if err != nil || len(all) != 2 || all[0].ID != 2 {
+		t.Errorf("Expected users in dataset order, got %v, %v", all, err)
+	}
+
+	user, err := store.Get(ctx, 1)
+	if err != nil || user.Name != "Ann Lee" {
+		t.Errorf("Expected Ann Lee, got %v, %v", user, err)
+	}
+	if _, err := store.Get(ctx, 42); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+
+	result, err := store.Search(ctx, SearchQuery{OrderField: "Age", OrderBy: OrderByAsc})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Users) != 2 || result.Users[0].ID != 1 || result.Version == "" {
+		t.Errorf("Expected users sorted by age with version, got %+v", result)
+	}
+}
+
+func TestXMLFileStore_ListGet(t *testing.T) {
+	store := NewXMLFileStore("dataset.xml")
+	ctx := context.Background()
+
+	all, err := store.List(ctx)
+	if err != nil || len(all) != 35 {
+		t.Fatalf("Expected 35 users, got %d, %v", len(all), err)
+	}
+
+	user, err := store.Get(ctx, 0)
+	if err != nil || user.Name != "Boyd Wolf" {
+		t.Errorf("Expected Boyd Wolf, got %v, %v", user, err)
+	}
+
+	missing := NewXMLFileStore("non_existent_file.xml")
+	if _, err := missing.List(ctx); err == nil {
+		t.Errorf("Expected List error for missing file, got nil")
+	}
+	if _, err := missing.Get(ctx, 0); err == nil {
+		t.Errorf("Expected Get error for missing file, got nil")
+	}
+}
+
+func TestSearchHandler_SeparateStores(t *testing.T) {
+	first := httptest.NewServer(NewSearchHandler(NewMemoryStore([]UserServer{{ID: 1, Name: "Ann Lee"}}), SearchHandlerOptions{}))
+	defer first.Close()
+	second := httptest.NewServer(NewSearchHandler(NewMemoryStore([]UserServer{{ID: 7, Name: "Bob Ray"}}), SearchHandlerOptions{}))
+	defer second.Close()
+
+	for url, expected := range map[string]string{first.URL: "Ann Lee", second.URL: "Bob Ray"} {
+		client := &SearchClient{AccessToken: "test_token", URL: url}
+		resp, err := client.FindUsers(SearchRequest{Limit: 10})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(resp.Users) != 1 || resp.Users[0].Name != expected {
+			t.Errorf("Expected only %s, got %v", expected, resp.Users)
+		}
+	}
+}
+
+func TestSearchHandler_Authorize(t *testing.T) {
+	handler := NewSearchHandler(NewMemoryStore(nil), SearchHandlerOptions{
+		Authorize: func(token string) bool { return token == "secret" },
+	})
+
+	for token, expected := range map[string]int{"secret": http.StatusOK, "other": http.StatusUnauthorized} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("AccessToken", token)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != expected {
+			t.Errorf("Expected status %v for token %q, got %v", expected, token, rr.Code)
+		}
+	}
+}
+
+func TestParseBalance(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected int64
+		isError  bool
+	}{
+		{"$2,144.93", 214493, false},
+		{"$1,000", 100000, false},
+		{"$3.5", 350, false},
+		{"-$12.01", -1201, false},
+		{"", 0, false},
+		{"$1.234", 0, true},
+		{"$abc", 0, true},
+		{"$1.x", 0, true},
+		{"$-5.50", -550, false},
+		{"$1.+5", 0, true},
+		{"-$-5", 0, true},
+		{"$+5", 0, true},
+		{"$.50", 0, true},
+		{"$99999999999999999999", 0, true},
+	}
+	for _, c := range cases {
+		got, err := parseBalance(c.value)
+		if (err != nil) != c.isError {
+			t.Errorf("parseBalance(%q): unexpected error state %v", c.value, err)
+			continue
+		}
+		if got != c.expected {
+			t.Errorf("parseBalance(%q): expected %d, got %d", c.value, c.expected, got)
+		}
+	}
+}
+
+func TestLoadDataset_InvalidTypedFields(t *testing.T) {
+	cases := map[string]string{
+		`invalid balance "$1.234"`:  `<root><row><id>1</id><balance>$1.234</balance></row></root>`,
+		`invalid registered "soon"`: `<root><row><id>1</id><registered>soon</registered></row></root>`,
+	}
+	for expected, content := range cases {
+		path := t.TempDir() + "/dataset.xml"
+		writeDatasetFile(t, path, content)
+
+		_, err := loadDataset(path)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestFindUsers_AllFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	resp, err := client.FindUsers(SearchRequest{Limit: 1, Query: "Boyd"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Users) != 1 {
+		t.Fatalf("expected 1 user, got %d", len(resp.Users))
+	}
+
+	expected := User{
+		ID:            0,
+		Name:          "Boyd Wolf",
+		Age:           22,
+		About:         resp.Users[0].About,
+		Gender:        "male",
+		GUID:          "1a6fa827-62f1-45f6-b579-aaead2b47169",
+		IsActive:      false,
+		Balance:       214493,
+		Picture:       "http://placehold.it/32x32",
+		EyeColor:      "green",
+		Company:       "HOPELI",
+		Email:         "boydwolf@hopeli.com",
+		Phone:         "+1 (956) 593-2402",
+		Address:       "586 Winthrop Street, Edneyville, Mississippi, 9555",
+		Registered:    time.Date(2017, 2, 5, 9, 23, 27, 0, time.UTC),
+		FavoriteFruit: "apple",
+	}
+	got := resp.Users[0]
+	if !got.Registered.Equal(expected.Registered) {
+		t.Errorf("Expected registered %v, got %v", expected.Registered, got.Registered)
+	}
+	got.Registered = expected.Registered
+	if got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestFindUsers_FieldsProjection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	resp, err := client.FindUsers(SearchRequest{Limit: 1, Query: "Boyd", Fields: []string{"ID", "email"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := User{ID: 0, Email: "boydwolf@hopeli.com"}
+	if len(resp.Users) != 1 || resp.Users[0] != expected {
+		t.Errorf("Expected only projected fields %+v, got %+v", expected, resp.Users)
+	}
+
+	// Проекция на все поля совпадает с ответом без fields
+	all := make([]string, len(userFields))
+	for i, f := range userFields {
+		all[i] = f.name
+	}
+	req := SearchRequest{Limit: 1, Query: "Boyd", OrderField: OrderFieldRelevance}
+	full, err := client.FindUsers(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	req.Fields = all
+	projected, err := client.FindUsers(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(projected.Users) != 1 || projected.Users[0] != full.Users[0] || full.Users[0].Balance == 0 || full.Users[0].Score == 0 {
+		t.Errorf("Expected projection on all fields to match full user %+v, got %+v", full.Users, projected.Users)
+	}
+
+	r := httptest.NewRequest("GET", "/?fields=ID,Password", nil)
+	r.Header.Set("AccessToken", "valid_token")
+	rr := httptest.NewRecorder()
+
+	SearchServer(rr, r)
+
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), `unknown field \"Password\"`) {
+		t.Errorf("Expected 400 for unknown field, got %v %q", rr.Code, rr.Body.String())
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestFindUsers_StructuredFilters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+
+	cases := []struct {
+		name     string
+		req      SearchRequest
+		expected []int
+	}{
+		{
+			name: "gender, age range and is_active",
+			req: SearchRequest{
+				Limit: 25, OrderField: "Id", OrderBy: OrderByAsc,
+				Gender: "Female", AgeMin: intPtr(30), AgeMax: intPtr(35), IsActive: boolPtr(true),
+			},
+			expected: []int{5, 7, 16, 25},
+		},
+		{
+			name:     "eye_color and favorite_fruit",
+			req:      SearchRequest{Limit: 25, OrderField: "Id", OrderBy: OrderByAsc, EyeColor: "brown", FavoriteFruit: "banana"},
+			expected: []int{9, 22},
+		},
+		{
+			name:     "company combined with query",
+			req:      SearchRequest{Limit: 25, Company: "callflex", Query: "Rose"},
+			expected: []int{9},
+		},
+		{
+			name:     "company not matching query",
+			req:      SearchRequest{Limit: 25, Company: "callflex", Query: "Boyd"},
+			expected: []int{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp, err := client.FindUsers(c.req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			ids := make([]int, 0, len(resp.Users))
+			for _, u := range resp.Users {
+				ids = append(ids, u.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(c.expected) {
+				t.Errorf("Expected users %v, got %v", c.expected, ids)
+			}
+		})
+	}
+}
+
+func TestValidateParams_Errors(t *testing.T) {
+	cases := map[string]string{
+		"/?limit=-1":                 "invalid limit: -1",
+		"/?offset=-5":                "invalid offset: -5",
+		"/?order_by=x":               "invalid order_by: x",
+		"/?age_min=old":              "invalid age_min: old",
+		"/?age_max=-3":               "invalid age_max: -3",
+		"/?age_min=40&age_max=30":    "invalid age range",
+		"/?is_active=maybe":          "invalid is_active: maybe",
+		"/?gender=robot":             "invalid gender: robot",
+		"/?fields=ID,Unknown":        `unknown field "Unknown"`,
+		"/?order_field=Id&age_min=1": "",
+	}
+	for target, expected := range cases {
+		_, err := validateParams(httptest.NewRequest("GET", target, nil))
+		if expected == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", target, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error containing %q, got %v", target, expected, err)
+		}
+	}
+}
+
+func TestInvertedIndex_Search(t *testing.T) {
+	idx := newInvertedIndex([]UserServer{
+		{ID: 0, Name: "Ann Lee", About: "red green blue"},
+		{ID: 1, Name: "Bob Green", About: "blue red"},
+		{ID: 2, Name: "Ann Bobson", About: "green, red"},
+	}, matchTransforms[MatchExact])
+
+	cases := map[string][]int{
+		"Ann":           {0, 2},
+		"Ann red":       {0, 2},
+		"Bob*":          {1, 2},
+		"Bo* Ann":       {2},
+		`"green blue"`:  {0},
+		`"Green blue"`:  nil, // фраза не переходит из Name в About
+		`"red gr*"`:     {0},
+		`"Lee red"`:     nil,
+		"Ann Unknown":   nil,
+		"Unknown* blue": nil,
+		"...":           nil,
+	}
+	ds := &dataset{users: make([]UserServer, 3)}
+	for query, expected := range cases {
+		expr, err := parseQuery(query)
+		if err != nil {
+			t.Fatalf("parseQuery(%q): unexpected error %v", query, err)
+		}
+		got := expr.eval(&queryEval{ds: ds, idx: idx})
+		if fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Errorf("search(%q): expected %v, got %v", query, expected, got)
+		}
+	}
+}
+
+func TestFindUsers_QueryModes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+
+	// "on" не встречается как отдельное слово, но есть как подстрока
+	resp, err := client.FindUsers(SearchRequest{Limit: 25, Query: "on"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Users) != 0 {
+		t.Errorf("Expected no users for word query, got %d", len(resp.Users))
+	}
+
+	resp, err = client.FindUsers(SearchRequest{Limit: 1, Query: "on", QueryMode: QueryModeSubstring, OrderField: "Age", OrderBy: OrderByDesc})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Users) != 1 || resp.Users[0].Age != 40 || !resp.NextPage {
+		t.Errorf("Expected the oldest user with substring match, got %+v", resp.Users)
+	}
+
+	cases := map[string][]int{
+		"Hil*":          {1},
+		`"nisi mollit"`: {0},
+		"nisi mollit":   {0, 1, 6, 7, 11, 12, 13, 21, 27, 28, 29, 34},
+	}
+	for query, expected := range cases {
+		resp, err := client.FindUsers(SearchRequest{Limit: 25, Query: query, OrderField: "Id", OrderBy: OrderByAsc})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ids := make([]int, 0, len(resp.Users))
+		for _, u := range resp.Users {
+			ids = append(ids, u.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(expected) {
+			t.Errorf("query %q: expected %v, got %v", query, expected, ids)
+		}
+	}
+
+	_, err = validateParams(httptest.NewRequest("GET", "/?query_mode=regexp", nil))
+	if err == nil || err.Error() != "invalid query_mode: regexp" {
+		t.Errorf("Expected invalid query_mode error, got %v", err)
+	}
+}
+
+// Синтетический набор из n пользователей по схеме dataset.xml:
+// имена и описания собираются из слов реальных записей
+func syntheticUsers(b testing.TB, n int) []UserServer {
+	b.Helper()
+	base, err := NewXMLFileStore("dataset.xml").List(context.Background())
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	users := make([]UserServer, n)
+	for i := range users {
+		first, second := base[i%len(base)], base[(i/len(base)+i)%len(base)]
+		words := tokenize(first.About)
+		users[i] = first
+		users[i].ID = i
+		users[i].Name = tokenize(first.Name)[0] + " " + tokenize(second.Name)[1]
+		users[i].Age = 18 + i%50
+		users[i].About = strings.Join(words[i%len(words):], " ") + " " + second.About
+	}
+	return users
+}
+
+func BenchmarkSearch(b *testing.B) {
+	ds := newDataset(syntheticUsers(b, 100000), time.Now())
+	queries := map[string]SearchQuery{
+		"index/word":       {Query: "Boyd", OrderField: "Age", OrderBy: OrderByAsc},
+		"index/and":        {Query: "nisi mollit", OrderField: "Age", OrderBy: OrderByAsc},
+		"index/prefix":     {Query: "Hil*", OrderField: "Age", OrderBy: OrderByAsc},
+		"index/phrase":     {Query: `"nisi mollit"`, OrderField: "Age", OrderBy: OrderByAsc},
+		"substring/word":   {Query: "Boyd", QueryMode: QueryModeSubstring, OrderField: "Age", OrderBy: OrderByAsc},
+		"substring/phrase": {Query: "nisi mollit", QueryMode: QueryModeSubstring, OrderField: "Age", OrderBy: OrderByAsc},
+	}
+	for name, q := range queries {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				filterAndSortUsers(context.Background(), ds, q) //nolint:errcheck
+			}
+		})
+	}
+}
+
+func BenchmarkNewDataset(b *testing.B) {
+	users := syntheticUsers(b, 100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newDataset(users, time.Now())
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	cases := map[string][2]string{
+		"Boyd Wolf":    {"boyd wolf", "boyd wolf"},
+		"Ёлка ЙОД":     {"ёлка йод", "елка иод"},
+		"Crème Brûlée": {"crème brûlée", "creme brulee"},
+		"Café":         {"café", "cafe"}, // e + комбинируемый акцент
+		"ΟΔΟΣ Straße":  {"οδοσ strasse", "οδοσ strasse"},
+		"ＡＢＣ１ ﬁsh":     {"ａｂｃ１ fish", "abc1 fish"},
+		"Tiếng Việt":   {"tiếng việt", "tieng viet"},
+		"Άννα ΣΊΣΥΦΟΣ": {"άννα σίσυφοσ", "αννα σισυφοσ"},
+		"Łódź":         {"łódź", "łodz"},
+		"Meſſe":        {"messe", "messe"},
+		"ℌilbert №5":   {"ℌilbert №5", "hilbert no5"},
+	}
+	for input, expected := range cases {
+		if got := foldCase(input); got != expected[0] {
+			t.Errorf("foldCase(%q): expected %q, got %q", input, expected[0], got)
+		}
+		if got := normalizeText(input); got != expected[1] {
+			t.Errorf("normalizeText(%q): expected %q, got %q", input, expected[1], got)
+		}
+	}
+}
+
+func TestSearchHandler_MatchModes(t *testing.T) {
+	store := NewMemoryStore([]UserServer{
+		{ID: 1, Name: "Boyd Wolf", About: "Crème brûlée lover"},
+		{ID: 2, Name: "Ёжик Туманов", About: "Живёт в тумане"},
+		{ID: 3, Name: "boyd lowercase", About: "creme"},
+		{ID: 4, Name: "Nguyễn Văn An", About: "Tiếng Việt"},
+		{ID: 5, Name: "Άννα Παπαδοπούλου", About: "ΟΔΟΣ"},
+	})
+	defaultServer := httptest.NewServer(NewSearchHandler(store, SearchHandlerOptions{}))
+	defer defaultServer.Close()
+	exactServer := httptest.NewServer(NewSearchHandler(store, SearchHandlerOptions{DefaultMatch: MatchExact}))
+	defer exactServer.Close()
+	legacyServer := httptest.NewServer(NewSearchHandler(store, SearchHandlerOptions{LegacyQuery: true}))
+	defer legacyServer.Close()
+
+	cases := []struct {
+		url      string
+		req      SearchRequest
+		expected []int
+	}{
+		{defaultServer.URL, SearchRequest{Query: "boyd"}, []int{1, 3}},
+		{defaultServer.URL, SearchRequest{Query: "BOYD", Match: MatchExact}, []int{}},
+		{defaultServer.URL, SearchRequest{Query: "Boyd", Match: MatchExact}, []int{1}},
+		{defaultServer.URL, SearchRequest{Query: "ЁЖИК"}, []int{2}},
+		{defaultServer.URL, SearchRequest{Query: "ежик"}, []int{}},
+		{defaultServer.URL, SearchRequest{Query: "ежик", Match: MatchNormalized}, []int{2}},
+		{defaultServer.URL, SearchRequest{Query: "creme", Match: MatchNormalized}, []int{1, 3}},
+		{defaultServer.URL, SearchRequest{Query: "ЖИВЕТ В", QueryMode: QueryModeSubstring, Match: MatchNormalized}, []int{2}},
+		{defaultServer.URL, SearchRequest{Query: "ЖИВЁТ В", QueryMode: QueryModeSubstring}, []int{2}},
+		{defaultServer.URL, SearchRequest{Query: "tieng viet", Match: MatchNormalized}, []int{4}},
+		{defaultServer.URL, SearchRequest{Query: "NGUYỄN"}, []int{4}},
+		{defaultServer.URL, SearchRequest{Query: "αννα", Match: MatchNormalized}, []int{5}},
+		{defaultServer.URL, SearchRequest{Query: "ΆΝΝΑ"}, []int{5}},
+		{defaultServer.URL, SearchRequest{Query: "οδος", Match: MatchNormalized}, []int{5}},
+		{exactServer.URL, SearchRequest{Query: "boyd"}, []int{3}},
+		{exactServer.URL, SearchRequest{Query: "boyd", Match: MatchCaseInsensitive}, []int{1, 3}},
+		{exactServer.URL, SearchRequest{Query: "oyd"}, []int{}},
+		{legacyServer.URL, SearchRequest{Query: "oyd"}, []int{1, 3}},
+		{legacyServer.URL, SearchRequest{Query: "d W"}, []int{1}},
+		{legacyServer.URL, SearchRequest{Query: "OYD"}, []int{}},
+		{legacyServer.URL, SearchRequest{Query: "oyd", QueryMode: QueryModeIndex}, []int{}},
+		{legacyServer.URL, SearchRequest{Query: "OYD", Match: MatchCaseInsensitive}, []int{1, 3}},
+	}
+	for _, c := range cases {
+		client := &SearchClient{AccessToken: "test_token", URL: c.url}
+		c.req.Limit = 10
+		c.req.OrderField = "Id"
+		c.req.OrderBy = OrderByAsc
+		resp, err := client.FindUsers(c.req)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ids := make([]int, 0, len(resp.Users))
+		for _, u := range resp.Users {
+			ids = append(ids, u.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(c.expected) {
+			t.Errorf("%+v: expected %v, got %v", c.req, c.expected, ids)
+		}
+	}
+
+	_, err := validateParams(httptest.NewRequest("GET", "/?match=fuzzy", nil))
+	if err == nil || err.Error() != "invalid match: fuzzy" {
+		t.Errorf("Expected invalid match error, got %v", err)
+	}
+}
+
+// Пример из README с прежним поведением: самый старший из тех, у кого
+// в Name или About есть строка "on". Поле сортировки, как и раньше,
+// пишется с заглавной буквы
+func TestSearchHandler_LegacyQueryReadmeExample(t *testing.T) {
+	all, err := testStore.Search(context.Background(), SearchQuery{OrderField: "Age", OrderBy: OrderByDesc})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var expected *UserServer
+	for i, u := range all.Users {
+		if strings.Contains(u.Name, "on") || strings.Contains(u.About, "on") {
+			expected = &all.Users[i]
+			break
+		}
+	}
+	if expected == nil {
+		t.Fatal("Expected dataset users with \"on\"")
+	}
+
+	handler := NewSearchHandler(testStore, SearchHandlerOptions{LegacyQuery: true})
+	r := httptest.NewRequest("GET", "/?order_by=-1&order_field=Age&limit=1&offset=0&query=on", nil)
+	r.Header.Set("AccessToken", "test_token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	var users []UserServer
+	if err := json.Unmarshal(w.Body.Bytes(), &users); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("Expected 200 with users, got %d %s", w.Code, w.Body.String())
+	}
+	if len(users) != 1 || users[0].ID != expected.ID {
+		t.Errorf("Expected user %d, got %+v", expected.ID, users)
+	}
+
+	// Ответы с разными режимами по умолчанию различаются и по ETag
+	r = httptest.NewRequest("GET", "/?order_by=-1&order_field=Age&limit=1&offset=0&query=on", nil)
+	r.Header.Set("AccessToken", "test_token")
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	NewSearchHandler(testStore, SearchHandlerOptions{DefaultMatch: MatchExact}).ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected full response for another default query mode, got %d", w.Code)
+	}
+}
+
+func TestCollation(t *testing.T) {
+	// Слова в порядке возрастания для каждого языка
+	cases := map[string][]string{
+		"":   {"Äng", "Åsa", "Östen", "Zorro"},
+		"de": {"Äng", "Åsa", "Östen", "Zorro"},
+		"sv": {"Zorro", "Åsa", "Äng", "Östen"},
+		"da": {"Zorro", "Æble", "Øre", "Åse"},
+		"es": {"Nunez", "Nunz", "Núñez", "Ñandú", "Ojo"},
+		"cs": {"Cyril", "Čapek", "Dana", "Hrad", "Chata", "Info", "Rybář", "Řeka", "Sova", "Sýr", "Šárka", "Zuzana", "Žofie"},
+		"pl": {"Lwy", "Łódź", "Mama", "Zyta", "Źdźbło", "Żaba"},
+		"ru": {"Ежи", "Ёжик", "Ель", "Иван", "Йод", "Кот"},
+		"uk": {"Гуска", "Ґанок", "Дім", "Ехо", "Євро", "Жовтень", "Ирій", "Ірина", "Їжак", "Йод", "Кіт"},
+	}
+	for locale, words := range cases {
+		coll := collations[locale]
+		for k := 1; k < len(words); k++ {
+			if a, b := coll.key(words[k-1]), coll.key(words[k]); a >= b {
+				t.Errorf("%q: expected %q before %q, got keys %q and %q", locale, words[k-1], words[k], a, b)
+			}
+		}
+	}
+	if sv := collations["sv"]; sv.key("Ærla") != sv.key("Ärla") || sv.key("Øl") != sv.key("Öl") {
+		t.Error("Expected æ and ø to sort as ä and ö in Swedish")
+	}
+
+	for value, expected := range map[string]string{"": "", "sv-SE": "sv", "CS_cz": "cs", "sv-": "sv"} {
+		if got, ok := parseLocale(value); got != expected || !ok {
+			t.Errorf("parseLocale(%q): expected %q, got %q, %v", value, expected, got, ok)
+		}
+	}
+	if _, ok := parseLocale("xx"); ok {
+		t.Error("Expected unknown locale to be rejected")
+	}
+	if h := NewSearchHandler(testStore, SearchHandlerOptions{DefaultLocale: "xx"}); h.opts.DefaultLocale != "" {
+		t.Errorf("Expected unknown default locale to fall back to the common order, got %q", h.opts.DefaultLocale)
+	}
+}
+
+func TestSearchHandler_Locale(t *testing.T) {
+	store := NewMemoryStore([]UserServer{
+		{ID: 1, Name: "Östen", Age: 30},
+		{ID: 2, Name: "Zorro", Age: 30},
+		{ID: 3, Name: "Åsa", Age: 20},
+		{ID: 4, Name: "Anna", Age: 30},
+		{ID: 5, Name: "Äng", Age: 20},
+	})
+	ts := httptest.NewServer(NewSearchHandler(store, SearchHandlerOptions{}))
+	defer ts.Close()
+	swedish := httptest.NewServer(NewSearchHandler(store, SearchHandlerOptions{DefaultLocale: "sv-SE"}))
+	defer swedish.Close()
+
+	cases := []struct {
+		url      string
+		req      SearchRequest
+		expected []int
+	}{
+		{ts.URL, SearchRequest{OrderField: "Name", OrderBy: OrderByAsc}, []int{5, 4, 3, 1, 2}},
+		{ts.URL, SearchRequest{OrderField: "Name", OrderBy: OrderByAsc, Locale: "sv"}, []int{4, 2, 3, 5, 1}},
+		{ts.URL, SearchRequest{OrderField: "Name", OrderBy: OrderByDesc, Locale: "sv"}, []int{1, 5, 3, 2, 4}},
+		{ts.URL, SearchRequest{Sort: []SortKey{{Field: "Age", Order: OrderByDesc}, {Field: "Name", Order: OrderByAsc}}, Locale: "sv"}, []int{4, 2, 1, 3, 5}},
+		{ts.URL, SearchRequest{OrderField: "Age", OrderBy: OrderByAsc, Locale: "sv"}, []int{3, 5, 1, 2, 4}},
+		{swedish.URL, SearchRequest{OrderField: "Name", OrderBy: OrderByAsc}, []int{4, 2, 3, 5, 1}},
+		{swedish.URL, SearchRequest{OrderField: "Name", OrderBy: OrderByAsc, Locale: "de"}, []int{5, 4, 3, 1, 2}},
+	}
+	for _, c := range cases {
+		client := &SearchClient{AccessToken: "test_token", URL: c.url}
+		c.req.Limit = 10
+		resp, err := client.FindUsers(c.req)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if ids := userIDs(resp.Users); fmt.Sprint(ids) != fmt.Sprint(c.expected) {
+			t.Errorf("%+v: expected %v, got %v", c.req, c.expected, ids)
+		}
+	}
+
+	// Курсор продолжает список в порядке языка
+	client := NewSearchClient("test_token", ts.URL)
+	req := SearchRequest{Limit: 2, OrderField: "Name", OrderBy: OrderByAsc, Locale: "sv"}
+	resp, err := client.FindUsers(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	paged := resp.Users
+	for resp.NextPage {
+		if resp, err = client.FindNextUsers(req, resp); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		paged = append(paged, resp.Users...)
+	}
+	if ids := userIDs(paged); fmt.Sprint(ids) != fmt.Sprint([]int{4, 2, 3, 5, 1}) {
+		t.Errorf("Expected Swedish order across cursor pages, got %v", ids)
+	}
+
+	_, err = client.FindUsers(SearchRequest{Locale: "xx"})
+	var searchErr *SearchError
+	if !errors.As(err, &searchErr) || searchErr.Param != "locale" || err.Error() != "unknown bad request error: invalid locale: xx" {
+		t.Errorf("Expected invalid locale error, got %v", err)
+	}
+}
+
+func TestFindUsers_QueryLanguage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+
+	cases := map[string][]int{
+		"gender:female age:>35":                                {9, 32, 33},
+		"name:hil* OR name:Boyd":                               {0, 1},
+		"(age:<25 OR age:>=39) NOT gender:male is_active:TRUE": {32},
+		`about:"nisi mollit"`:                                  {0},
+		"name:nisi":                                            {},
+		"company:HOPELI OR eye_color:blue AND id:<=5":          {0, 2, 3, 4},
+		"Boyd AND Wolf":                                        {0},
+		"NOT NOT Boyd":                                         {0},
+		`favorite_fruit:apple email:"boydwolf@hopeli.com" age:22`:    {0},
+		"Boyd (Hilda OR Wolf) AND NOT (age:>22 OR is_active:true)":   {0},
+		"company:nonexistent OR (name:boyd AND gender:male age:=22)": {0},
+	}
+	for query, expected := range cases {
+		resp, err := client.FindUsers(SearchRequest{Limit: 25, Query: query, OrderField: "Id", OrderBy: OrderByAsc})
+		if err != nil {
+			t.Fatalf("query %q: unexpected error: %s", query, err)
+		}
+		ids := make([]int, 0, len(resp.Users))
+		for _, u := range resp.Users {
+			ids = append(ids, u.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(expected) {
+			t.Errorf("query %q: expected %v, got %v", query, expected, ids)
+		}
+	}
+}
+
+func TestParseQuery_SyntaxErrors(t *testing.T) {
+	cases := map[string]string{
+		"age:old":           "position 5: expected number for age but found \"old\"",
+		"is_active:maybe":   "position 11: expected true or false for is_active but found \"maybe\"",
+		"name: AND":         "position 7: expected value for name but found \"AND\"",
+		"Boyd OR (gender:)": "position 17: expected value for gender but found \")\"",
+		"email:":            "position 7: expected value for email but found \"end of query\"",
+		"NOT age:x":         "position 9: expected number for age but found \"x\"",
+	}
+	for query, expected := range cases {
+		_, err := parseQuery(query)
+		var syntaxErr *QuerySyntaxError
+		if !errors.As(err, &syntaxErr) || !strings.HasSuffix(err.Error(), expected) {
+			t.Errorf("parseQuery(%q): expected error ending with %q, got %v", query, expected, err)
+		}
+	}
+
+	for _, query := range []string{"", "   "} {
+		if expr, err := parseQuery(query); expr != nil || err != nil {
+			t.Errorf("parseQuery(%q): expected empty query, got %v, %v", query, expr, err)
+		}
+	}
+}
+
+// Обычный текст с двоеточиями, непарными скобками и кавычками ищется как
+// текст, а не отклоняется как ошибка синтаксиса
+func TestFindUsers_QueryPlainText(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	cases := map[string][]int{
+		"Boyd:Wolf":       {0},
+		"foo:bar":         {},
+		"salary:>10":      {},
+		"(Boyd OR Wolf":   {0},
+		"Hilda)":          {1},
+		"Boyd(Wolf":       {0},
+		`Boyd "Wolf`:      {0},
+		`"Boyd Wolf" "x`:  {},
+		"Ёжик :x":         {},
+		`name:"boyd wolf`: {0},
+		"(Boyd) OR Hilda": {0, 1},
+		"()":              {},
+		"Boyd ( )":        {},
+	}
+	for query, expected := range cases {
+		resp, err := client.FindUsers(SearchRequest{Limit: 25, Query: query, OrderField: "Id", OrderBy: OrderByAsc})
+		if err != nil {
+			t.Fatalf("query %q: unexpected error: %s", query, err)
+		}
+		if fmt.Sprint(userIDs(resp.Users)) != fmt.Sprint(expected) {
+			t.Errorf("query %q: expected %v, got %v", query, expected, userIDs(resp.Users))
+		}
+	}
+
+	// Оператор без операнда - слово, например код штата OR
+	words := httptest.NewServer(NewSearchHandler(NewMemoryStore([]UserServer{
+		{ID: 1, Name: "Ann", About: "lives in OR"},
+		{ID: 2, Name: "Bob", About: "do NOT disturb"},
+		{ID: 3, Name: "Cid", About: "and so on"},
+	}), SearchHandlerOptions{}))
+	defer words.Close()
+	client = &SearchClient{AccessToken: "test_token", URL: words.URL}
+	for query, expected := range map[string][]int{
+		"NOT":         {2},
+		"OR":          {1},
+		"Ann OR":      {1},
+		"Cid AND":     {3},
+		"Bob AND":     {},
+		"(Ann OR)":    {1},
+		"NOT (OR)":    {2, 3},
+		"OR NOT":      {},
+		"Ann OR NOT":  {1, 2},
+		"Ann OR OR":   {1},
+		"AND so":      {3},
+		"Cid AND ( )": {},
+	} {
+		resp, err := client.FindUsers(SearchRequest{Limit: 25, Query: query, OrderField: "Id", OrderBy: OrderByAsc})
+		if err != nil {
+			t.Fatalf("query %q: unexpected error: %s", query, err)
+		}
+		if fmt.Sprint(userIDs(resp.Users)) != fmt.Sprint(expected) {
+			t.Errorf("query %q: expected %v, got %v", query, expected, userIDs(resp.Users))
+		}
+	}
+}
+
+func TestFindUsers_QuerySyntaxErrorResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	_, err := client.FindUsers(SearchRequest{Limit: 1, Query: "age:>30 AND (gender:)"})
+
+	expected := `unknown bad request error: query syntax error at position 21: expected value for gender but found ")"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	// В режиме подстроки запрос не разбирается
+	resp, err := client.FindUsers(SearchRequest{Limit: 1, Query: "(", QueryMode: QueryModeSubstring})
+	if err != nil || len(resp.Users) != 0 {
+		t.Errorf("expected no users and no error for substring mode, got %v, %v", resp, err)
+	}
+}
+
+func TestSearchHandler_RelevanceOrder(t *testing.T) {
+	store := NewMemoryStore([]UserServer{
+		{ID: 1, Name: "Ann Lee", About: "likes green tea"},
+		{ID: 2, Name: "Green Ray", About: "quiet"},
+		{ID: 3, Name: "Bob Stone", About: "green green green fields"},
+		{ID: 4, Name: "Cid Moss", About: "likes green tea"},
+		{ID: 5, Name: "Dan Wood", About: "no match here"},
+	})
+	ts := httptest.NewServer(NewSearchHandler(store, SearchHandlerOptions{}))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+
+	// Совпадение в Name весит больше, повторы в About - больше одиночного,
+	// равные оценки упорядочены по ID
+	resp, err := client.FindUsers(SearchRequest{Limit: 10, Query: "green", OrderField: OrderFieldRelevance, OrderBy: OrderByDesc})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ids := make([]int, 0, len(resp.Users))
+	for _, u := range resp.Users {
+		ids = append(ids, u.ID)
+	}
+	if fmt.Sprint(ids) != "[2 3 1 4]" {
+		t.Errorf("Expected relevance order [2 3 1 4], got %v", ids)
+	}
+	for k := 1; k < len(resp.Users); k++ {
+		if resp.Users[k].Score > resp.Users[k-1].Score || resp.Users[k].Score <= 0 {
+			t.Errorf("Expected positive non-increasing scores, got %+v", resp.Users)
+		}
+	}
+	if resp.Users[2].Score != resp.Users[3].Score {
+		t.Errorf("Expected equal scores for identical matches, got %v and %v", resp.Users[2].Score, resp.Users[3].Score)
+	}
+
+	// Без направления, как по умолчанию шлёт клиент, - тоже от лучших к худшим
+	for _, req := range []SearchRequest{
+		{Limit: 10, Query: "green", OrderField: OrderFieldRelevance},
+		{Limit: 10, Query: "green", Sort: []SortKey{{Field: OrderFieldRelevance, Order: OrderByDesc}, {Field: "Age", Order: OrderByAsc}}},
+	} {
+		resp, err := client.FindUsers(req)
+		if err != nil || fmt.Sprint(userIDs(resp.Users)) != "[2 3 1 4]" {
+			t.Errorf("%+v: expected best matches first [2 3 1 4], got %v, %v", req, userIDs(resp.Users), err)
+		}
+	}
+	for target, expected := range map[string]string{
+		"/?order_field=Relevance":                    "[{Relevance -1}]",
+		"/?order_field=Relevance,Id":                 "[{Relevance -1} {Id 1}]",
+		"/?order_field=Id:desc,Relevance&order_by=1": "[{Id -1} {Relevance 1}]",
+	} {
+		params, err := validateParams(httptest.NewRequest("GET", target, nil))
+		if err != nil || fmt.Sprint(params.query.sortKeys()) != expected {
+			t.Errorf("%s: expected sort keys %s, got %v, %v", target, expected, params.query.sortKeys(), err)
+		}
+	}
+
+	resp, err = client.FindUsers(SearchRequest{Limit: 10, Query: "green NOT stone", OrderField: OrderFieldRelevance, OrderBy: OrderByAsc})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ids = ids[:0]
+	for _, u := range resp.Users {
+		ids = append(ids, u.ID)
+	}
+	if fmt.Sprint(ids) != "[1 4 2]" {
+		t.Errorf("Expected ascending relevance order [1 4 2], got %v", ids)
+	}
+
+	resp, err = client.FindUsers(SearchRequest{Limit: 10, Query: "gree", QueryMode: QueryModeSubstring, OrderField: OrderFieldRelevance})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Users) != 4 || resp.Users[0].ID != 1 || resp.Users[0].Score != 0 {
+		t.Errorf("Expected substring matches ordered by ID with zero scores, got %+v", resp.Users)
+	}
+}
+
+func TestFindUsers_RelevanceOnDataset(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	resp, err := client.FindUsers(SearchRequest{Limit: 3, Query: "Boyd OR Hil* OR nisi", OrderField: OrderFieldRelevance, OrderBy: OrderByDesc, Fields: []string{"ID", "Score"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Users) != 3 || resp.Users[0].ID+resp.Users[1].ID != 1 {
+		t.Errorf("Expected name matches Boyd Wolf and Hilda Mayer first, got %+v", resp.Users)
+	}
+	if resp.Users[0].Score <= resp.Users[2].Score {
+		t.Errorf("Expected name match to score above about match, got %+v", resp.Users)
+	}
+}
+
+func TestFilterAndSortUsers_AsIsAndTies(t *testing.T) {
+	users := []UserServer{
+		{ID: 3, Name: "Cid", Age: 30},
+		{ID: 1, Name: "Ann", Age: 25},
+		{ID: 4, Name: "Bob", Age: 30},
+		{ID: 2, Name: "Dan", Age: 25},
+	}
+	ds := newDataset(users, time.Now())
+
+	cases := []struct {
+		query    SearchQuery
+		expected string
+	}{
+		{SearchQuery{OrderField: "Age", OrderBy: OrderByAsIs}, "[3 1 4 2]"},
+		{SearchQuery{OrderField: "Name", OrderBy: OrderByAsIs}, "[3 1 4 2]"},
+		{SearchQuery{OrderField: "Age", OrderBy: OrderByAsc}, "[1 2 3 4]"},
+		{SearchQuery{OrderField: "Age", OrderBy: OrderByDesc}, "[3 4 1 2]"},
+		{SearchQuery{OrderField: "Name", OrderBy: OrderByDesc}, "[2 3 4 1]"},
+	}
+	for _, c := range cases {
+		sorted, err := filterAndSortUsers(context.Background(), ds, c.query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids := make([]int, 0, len(sorted))
+		for _, u := range sorted {
+			ids = append(ids, u.ID)
+		}
+		if fmt.Sprint(ids) != c.expected {
+			t.Errorf("%+v: expected %s, got %v", c.query, c.expected, ids)
+		}
+	}
+
+	for target, expected := range map[string]string{
+		"/?order_by=2":  "invalid order_by: 2",
+		"/?order_by=-5": "invalid order_by: -5",
+	} {
+		_, err := validateParams(httptest.NewRequest("GET", target, nil))
+		if err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, got %v", target, expected, err)
+		}
+	}
+}
+
+func TestFindUsers_PagesConcatenateToFullList(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+
+	for _, field := range []string{"Id", "Age", "Name", "Balance", "Registered", "Company", "Email", OrderFieldRelevance} {
+		for _, orderBy := range []int{OrderByAsc, OrderByDesc, OrderByAsIs} {
+			for _, query := range []string{"", "nisi OR Boyd"} {
+				name := fmt.Sprintf("%s/%d/%q", field, orderBy, query)
+				t.Run(name, func(t *testing.T) {
+					full, err := testStore.Search(context.Background(), SearchQuery{Query: query, OrderField: field, OrderBy: orderBy})
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					assertStrictOrder(t, full.Users, field, orderBy)
+
+					var paged []User
+					for offset := 0; ; offset += 4 {
+						resp, err := client.FindUsers(SearchRequest{Limit: 4, Offset: offset, Query: query, OrderField: field, OrderBy: orderBy})
+						if err != nil {
+							t.Fatalf("unexpected error: %s", err)
+						}
+						paged = append(paged, resp.Users...)
+						if !resp.NextPage {
+							break
+						}
+					}
+
+					if len(paged) != len(full.Users) {
+						t.Fatalf("Expected %d users across pages, got %d", len(full.Users), len(paged))
+					}
+					for k := range paged {
+						if paged[k].ID != full.Users[k].ID {
+							t.Fatalf("Position %d: expected user %d, got %d", k, full.Users[k].ID, paged[k].ID)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+// Проверка, что соседние пользователи упорядочены по полю, а при равенстве -
+// по ID без повторов
+func assertStrictOrder(t *testing.T, users []UserServer, field string, orderBy int) {
+	t.Helper()
+	for k := 1; k < len(users); k++ {
+		a, b := &users[k-1], &users[k]
+		var c int
+		order := orderBy
+		switch {
+		case field == OrderFieldRelevance:
+			// без направления - от лучших совпадений к худшим
+			c = cmp.Compare(a.Score, b.Score)
+			if order == OrderByAsIs {
+				order = OrderByDesc
+			}
+		case orderBy == OrderByAsIs:
+			// dataset.xml записан по возрастанию ID
+		default:
+			c = compareUsersBy(field, nil, a, b)
+		}
+		if c*order > 0 || c == 0 && a.ID >= b.ID {
+			t.Fatalf("Users %d and %d are out of order for %s/%d", a.ID, b.ID, field, orderBy)
+		}
+	}
+}
+
+func TestFindUsers_MultiKeySort(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	resp, err := client.FindUsers(SearchRequest{
+		Limit: 25,
+		Sort:  []SortKey{{Field: "Age", Order: OrderByDesc}, {Field: "Name", Order: OrderByAsc}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Users) != 25 {
+		t.Fatalf("Expected 25 users, got %d", len(resp.Users))
+	}
+	for k := 1; k < len(resp.Users); k++ {
+		a, b := resp.Users[k-1], resp.Users[k]
+		if a.Age < b.Age || a.Age == b.Age && a.Name > b.Name {
+			t.Fatalf("Users %d and %d are out of Age desc, Name asc order", a.ID, b.ID)
+		}
+	}
+
+	users := []UserServer{
+		{ID: 3, Name: "Cid", Age: 30},
+		{ID: 1, Name: "Ann", Age: 25},
+		{ID: 4, Name: "Bob", Age: 30},
+		{ID: 2, Name: "Ann", Age: 30},
+	}
+	ds := newDataset(users, time.Now())
+	cases := map[string]string{
+		"/?order_field=Age:desc,Name:asc":           "[2 4 3 1]",
+		"/?order_field=Age:DESC,Name:desc":          "[3 4 2 1]",
+		"/?order_field=Name,Age":                    "[1 2 4 3]",
+		"/?order_field=Name,Age:asc&order_by=-1":    "[3 4 1 2]",
+		"/?order_field=Name:asc,Id:desc":            "[2 1 4 3]",
+		"/?order_field=Age:asc,Relevance&query=Ann": "[1 2]",
+	}
+	for target, expected := range cases {
+		params, err := validateParams(httptest.NewRequest("GET", target, nil))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", target, err)
+		}
+		sorted, err := filterAndSortUsers(context.Background(), ds, params.query)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", target, err)
+		}
+		ids := make([]int, 0, len(sorted))
+		for _, u := range sorted {
+			ids = append(ids, u.ID)
+		}
+		if fmt.Sprint(ids) != expected {
+			t.Errorf("%s: expected %s, got %v", target, expected, ids)
+		}
+	}
+
+	for target, expected := range map[string]string{
+		"/?order_field=Age:up":       "invalid order_field: Age:up",
+		"/?order_field=Age,Phone":    "invalid order_field: Phone",
+		"/?order_field=Age,":         "invalid order_field: ",
+		"/?order_field=Age:asc,Age":  "invalid order_field: duplicate Age",
+		"/?order_field=Name:asc,Bad": "invalid order_field: Bad",
+	} {
+		_, err := validateParams(httptest.NewRequest("GET", target, nil))
+		if err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, got %v", target, expected, err)
+		}
+	}
+}
+
+func TestFilterAndSortUsers_TypedFields(t *testing.T) {
+	registered := func(value string) time.Time {
+		r, err := parseRegistered(value)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return r
+	}
+	users := []UserServer{
+		{ID: 1, Balance: 214493, Company: "Zolar", Email: "b@x.com", Registered: registered("2014-01-01T10:00:00 -03:00")},
+		{ID: 2, Balance: 99, Company: "émile", Email: "A@x.com", Registered: registered("2014-01-01T01:00:00 +03:00")},
+		{ID: 3, Balance: 100000, Company: "Emile", Email: "a@x.com", Registered: registered("2014-01-01T07:00:00 -05:00")},
+		{ID: 4, Balance: 99, Company: "apex", Email: "c@x.com", Registered: registered("2013-12-31T23:00:00 -03:00")},
+	}
+	ds := newDataset(users, time.Now())
+
+	cases := map[string]string{
+		// числами, а не строками "$2,144.93"
+		"/?order_field=Balance&order_by=1":  "[2 4 3 1]",
+		"/?order_field=Balance&order_by=-1": "[1 3 2 4]",
+		// 01:00 +03:00 раньше 23:00 -03:00 предыдущего дня
+		"/?order_field=Registered&order_by=1": "[2 4 3 1]",
+		// регистр и диакритика не влияют на первичный порядок
+		"/?order_field=Company&order_by=1":          "[4 3 2 1]",
+		"/?order_field=Email&order_by=1":            "[2 3 1 4]",
+		"/?order_field=Balance:asc,Company:desc":    "[2 4 3 1]",
+		"/?order_field=Balance:asc,Registered:desc": "[4 2 3 1]",
+	}
+	for target, expected := range cases {
+		params, err := validateParams(httptest.NewRequest("GET", target, nil))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", target, err)
+		}
+		sorted, err := filterAndSortUsers(context.Background(), ds, params.query)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", target, err)
+		}
+		ids := make([]int, 0, len(sorted))
+		for _, u := range sorted {
+			ids = append(ids, u.ID)
+		}
+		if fmt.Sprint(ids) != expected {
+			t.Errorf("%s: expected %s, got %v", target, expected, ids)
+		}
+	}
+}
+
+func TestFindUsers_Envelope(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	resp, err := client.FindUsers(SearchRequest{Limit: 10, Offset: 20, OrderField: "Id", OrderBy: OrderByAsc})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.Total != 35 || resp.Page != 3 || resp.Pages != 4 || !resp.NextPage || len(resp.Users) != 10 || resp.Users[0].ID != 20 {
+		t.Errorf("Unexpected page metadata: total %d, page %d of %d, next %v, %d users",
+			resp.Total, resp.Page, resp.Pages, resp.NextPage, len(resp.Users))
+	}
+
+	resp, err = client.FindUsers(SearchRequest{Limit: 0, Query: "Boyd"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.Total != 1 || resp.Page != 1 || resp.Pages != 0 {
+		t.Errorf("Unexpected page metadata for zero limit: %+v", resp)
+	}
+
+	// Сервер без конверта: общее число неизвестно
+	legacy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("offset") == "10" {
+			w.Write([]byte(`null`)) //nolint:errcheck
+			return
+		}
+		w.Write([]byte(`[{"ID": 1}]`)) //nolint:errcheck
+	}))
+	defer legacy.Close()
+	resp, err = (&SearchClient{AccessToken: "test_token", URL: legacy.URL}).FindUsers(SearchRequest{Limit: 5, Offset: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.Total != -1 || resp.Page != 2 || resp.Pages != 0 || len(resp.Users) != 1 {
+		t.Errorf("Unexpected legacy page metadata: %+v", resp)
+	}
+	resp, err = (&SearchClient{AccessToken: "test_token", URL: legacy.URL}).FindUsers(SearchRequest{Limit: 5, Offset: 10})
+	if err != nil || resp.Users == nil || len(resp.Users) != 0 || resp.NextPage {
+		t.Errorf("Expected empty non-nil page for null users, got %+v, %v", resp, err)
+	}
+
+	cases := map[string]string{
+		"/?limit=2&offset=33&order_field=Id&order_by=1&envelope=1": `{"users":[{"ID":33},{"ID":34}],"total":35,"offset":33,"limit":2,"next_offset":null}`,
+		"/?limit=2&offset=0&order_field=Id&order_by=1&envelope=1":  `{"users":[{"ID":0},{"ID":1}],"total":35,"offset":0,"limit":2,"next_offset":2,"next_cursor":"`,
+		"/?limit=2&query=Boyd&envelope=true":                       `{"users":[{"ID":0}],"total":1,"offset":0,"limit":2,"next_offset":null}`,
+		"/?limit=2&offset=40&envelope=1":                           `{"users":[],"total":35,"offset":40,"limit":2,"next_offset":null}`,
+		"/?limit=1&envelope=0":                                     `[{"ID":0}]`,
+		// лишняя строка lookahead не входит ни в limit, ни в next_offset
+		"/?limit=3&lookahead=1&envelope=1&order_field=Id&order_by=1":           `{"users":[{"ID":0},{"ID":1},{"ID":2}],"total":35,"offset":0,"limit":2,"next_offset":2,"next_cursor":"`,
+		"/?limit=3&offset=33&lookahead=1&envelope=1&order_field=Id&order_by=1": `{"users":[{"ID":33},{"ID":34}],"total":35,"offset":33,"limit":2,"next_offset":null}`,
+	}
+	for target, expected := range cases {
+		r := httptest.NewRequest("GET", target+"&fields=ID", nil)
+		r.Header.Set("AccessToken", "test_token")
+		w := httptest.NewRecorder()
+		SearchServer(w, r)
+		if got := strings.TrimSpace(w.Body.String()); !strings.HasPrefix(got, expected) {
+			t.Errorf("%s: expected %s, got %s", target, expected, got)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/?envelope=maybe", nil)
+	if _, err := validateParams(r); err == nil || err.Error() != "invalid envelope: maybe" {
+		t.Errorf("Expected invalid envelope error, got %v", err)
+	}
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(HeaderSearchEnvelope, "1")
+	if params, err := validateParams(r); err != nil || !params.envelope {
+		t.Errorf("Expected envelope from header, got %v, %v", params.envelope, err)
+	}
+	r = httptest.NewRequest("GET", "/?lookahead=maybe", nil)
+	if _, err := validateParams(r); err == nil || err.Error() != "invalid lookahead: maybe" {
+		t.Errorf("Expected invalid lookahead error, got %v", err)
+	}
+}
+
+func TestFindUsers_CursorPagination(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	for _, req := range []SearchRequest{
+		{Limit: 4, OrderField: "Age", OrderBy: OrderByDesc},
+		{Limit: 5, Query: "nisi OR Boyd", OrderField: OrderFieldRelevance, OrderBy: OrderByDesc},
+		{Limit: 3, Sort: []SortKey{{Field: "Age", Order: OrderByAsc}, {Field: "Name", Order: OrderByDesc}}},
+		{Limit: 6, Sort: []SortKey{{Field: "Registered", Order: OrderByDesc}, {Field: "Balance", Order: OrderByAsc}}},
+		{Limit: 7, OrderField: "Email"},
+	} {
+		full, err := client.FindUsers(SearchRequest{Limit: 25, Query: req.Query, OrderField: req.OrderField, OrderBy: req.OrderBy, Sort: req.Sort})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		resp, err := client.FindUsers(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		paged := resp.Users
+		for page := 2; resp.NextPage; page++ {
+			if resp, err = client.FindNextUsers(req, resp); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if resp.Page != page {
+				t.Errorf("Expected page %d, got %d", page, resp.Page)
+			}
+			paged = append(paged, resp.Users...)
+		}
+		if resp.NextCursor != "" {
+			t.Errorf("Expected no cursor on the last page, got %q", resp.NextCursor)
+		}
+		if _, err := client.FindNextUsers(req, resp); err == nil || err.Error() != "no next page" {
+			t.Errorf("Expected no next page error, got %v", err)
+		}
+
+		want := full.Users
+		if len(want) == 25 {
+			want = nil
+			for offset := 0; ; offset += 25 {
+				r, err := client.FindUsers(SearchRequest{Limit: 25, Offset: offset, Query: req.Query, OrderField: req.OrderField, OrderBy: req.OrderBy, Sort: req.Sort})
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				want = append(want, r.Users...)
+				if !r.NextPage {
+					break
+				}
+			}
+		}
+		if fmt.Sprint(userIDs(paged)) != fmt.Sprint(userIDs(want)) {
+			t.Errorf("%+v: cursor pages %v differ from full list %v", req, userIDs(paged), userIDs(want))
+		}
+	}
+}
+
+func TestSearchHandler_CursorErrors(t *testing.T) {
+	path := t.TempDir() + "/dataset.xml"
+	writeDatasetFile(t, path, twoRowsXML)
+	store := NewXMLFileStore(path)
+	handler := NewSearchHandler(store, SearchHandlerOptions{CursorSecret: []byte("secret")})
+
+	search := func(target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", target, nil)
+		r.Header.Set("AccessToken", "test_token")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	first := search("/?limit=1&order_field=Id&order_by=1")
+	cursor := first.Header().Get(HeaderNextCursor)
+	if cursor == "" {
+		t.Fatalf("Expected next cursor on the first page")
+	}
+	if w := search("/?limit=1&order_field=Id&order_by=1&cursor=" + cursor); w.Code != http.StatusOK || w.Header().Get(HeaderNextCursor) != "" {
+		t.Fatalf("Expected last page without cursor, got %d %q", w.Code, w.Header().Get(HeaderNextCursor))
+	}
+
+	other := NewSearchHandler(store, SearchHandlerOptions{CursorSecret: []byte("other")})
+	r := httptest.NewRequest("GET", "/?limit=1&order_field=Id&order_by=1&cursor="+cursor, nil)
+	r.Header.Set("AccessToken", "test_token")
+	w := httptest.NewRecorder()
+	other.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid cursor") {
+		t.Errorf("Expected invalid cursor for foreign secret, got %d %s", w.Code, w.Body.String())
+	}
+
+	cases := map[string]string{
+		"/?limit=1&order_field=Id&order_by=-1&cursor=" + cursor:         "query parameters differ",
+		"/?limit=1&order_field=Id&order_by=1&cursor=garbage":            "invalid cursor",
+		"/?limit=1&order_field=Id&order_by=1&cursor=a.b":                "invalid cursor",
+		"/?limit=1&order_field=Id&order_by=1&cursor=!.b":                "invalid cursor",
+		"/?limit=1&order_field=Id&order_by=1&offset=1&cursor=" + cursor: "mutually exclusive",
+	}
+	for target, expected := range cases {
+		if w := search(target); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), expected) {
+			t.Errorf("%s: expected 400 with %q, got %d %s", target, expected, w.Code, w.Body.String())
+		}
+	}
+
+	// Подписанный курсор с неразбираемым содержимым, без значений ключей
+	// сортировки или с несуществующим ID при порядке хранилища
+	forged := base64.RawURLEncoding.EncodeToString([]byte("{")) + "." +
+		base64.RawURLEncoding.EncodeToString(signCursor([]byte("secret"), []byte("{")))
+	if w := search("/?limit=1&order_field=Id&order_by=1&cursor=" + forged); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for forged cursor, got %d", w.Code)
+	}
+	ds, err := store.snapshot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q := SearchQuery{OrderField: "Id", OrderBy: OrderByAsc, Match: MatchCaseInsensitive}
+	asIs := SearchQuery{OrderField: "Id", Match: MatchCaseInsensitive}
+	for _, c := range []struct {
+		target string
+		cursor searchCursor
+	}{
+		{"/?limit=1&order_field=Id&order_by=1&cursor=", searchCursor{Version: ds.version, Query: queryHash(q), ID: 1}},
+		{"/?limit=1&order_field=Id&order_by=1&cursor=", searchCursor{Version: ds.version, Query: queryHash(q), ID: 1, Keys: []json.RawMessage{json.RawMessage(`"1"`)}}},
+		{"/?limit=1&order_field=Id&cursor=", searchCursor{Version: ds.version, Query: queryHash(asIs), ID: 100}},
+	} {
+		if w := search(c.target + encodeCursor([]byte("secret"), c.cursor)); w.Code != http.StatusBadRequest {
+			t.Errorf("%+v: expected 400 for cursor, got %d", c.cursor, w.Code)
+		}
+	}
+
+	// Позиция ищется по значениям ключей, а не по ID: курсор после
+	// несуществующего пользователя с ID 100 ведёт за конец списка
+	beyond := encodeCursor([]byte("secret"), searchCursor{Version: ds.version, Query: queryHash(q), ID: 100, Keys: []json.RawMessage{json.RawMessage(`100`)}})
+	if w := search("/?limit=1&order_field=Id&order_by=1&cursor=" + beyond); w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("Expected empty page after cursor beyond the last user, got %d %s", w.Code, w.Body.String())
+	}
+
+	// После перезагрузки файла курсор старой версии отклоняется
+	writeDatasetFile(t, path, strings.Replace(twoRowsXML, "<age>", "<age>1", 1))
+	if err := store.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w := search("/?limit=1&order_field=Id&order_by=1&cursor=" + cursor); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "stale cursor") {
+		t.Errorf("Expected stale cursor error, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func userIDs(users []User) []int {
+	ids := make([]int, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+func TestSearchClient_All(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+
+	client := &SearchClient{AccessToken: "test_token", URL: ts.URL}
+	req := SearchRequest{Limit: 4, OrderField: "Id", OrderBy: OrderByAsc}
+	for _, opts := range [][]AllOption{nil, {WithPrefetch(1)}, {WithPrefetch(3)}} {
+		var ids []int
+		for u, err := range client.All(context.Background(), req, opts...) {
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			ids = append(ids, u.ID)
+		}
+		if len(ids) != 35 || ids[0] != 0 || ids[34] != 34 {
+			t.Errorf("Expected all 35 users in ID order, got %v", ids)
+		}
+	}
+
+	// Досрочный выход из цикла останавливает загрузку
+	count := 0
+	for range client.All(context.Background(), req, WithPrefetch(2)) {
+		count++
+		if count == 5 {
+			break
+		}
+	}
+	if count != 5 {
+		t.Errorf("Expected to stop after 5 users, got %d", count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, opts := range [][]AllOption{nil, {WithPrefetch(1)}} {
+		for _, err := range client.All(ctx, req, opts...) {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context.Canceled, got %v", err)
+			}
+		}
+	}
+
+	// Отмена во время загрузки страницы - ошибка, а не конец обхода
+	ctx, cancel = context.WithCancel(context.Background())
+	next := prefetchPages(ctx, func() (*SearchResponse, error) {
+		cancel()
+		return &SearchResponse{Users: []User{{ID: 1}}, NextPage: true}, nil
+	}, 1)
+	if resp, err := next(); resp != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled after cancel during prefetch, got %v, %v", resp, err)
+	}
+
+	bad := &SearchClient{AccessToken: "", URL: ts.URL}
+	err := bad.Each(context.Background(), req, func(User) error { return nil })
+	if err == nil || err.Error() != "bad AccessToken" {
+		t.Errorf("Expected bad AccessToken, got %v", err)
+	}
+
+	stop := errors.New("stop")
+	seen := 0
+	err = client.Each(context.Background(), SearchRequest{Query: "nisi"}, func(u User) error {
+		seen++
+		return stop
+	})
+	if err != stop || seen != 1 {
+		t.Errorf("Expected callback error after one user, got %v after %d", err, seen)
+	}
+
+	// Сервер без курсоров: переход по смещению
+	legacy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+		var users []User
+		for id := offset; id < 7 && id < offset+limit; id++ {
+			users = append(users, User{ID: id})
+		}
+		json.NewEncoder(w).Encode(users) //nolint:errcheck
+	}))
+	defer legacy.Close()
+	var ids []int
+	err = (&SearchClient{AccessToken: "test_token", URL: legacy.URL}).Each(context.Background(), SearchRequest{Limit: 3}, func(u User) error {
+		ids = append(ids, u.ID)
+		return nil
+	}, WithPrefetch(1))
+	if err != nil || fmt.Sprint(ids) != "[0 1 2 3 4 5 6]" {
+		t.Errorf("Expected users 0..6 by offset, got %v, %v", ids, err)
+	}
+}
+
+func TestFindUsersContext_CancelAndDeadline(t *testing.T) {
+	// Сервер отвечает, только когда клиент отменит запрос
+	blocking := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer blocking.Close()
+	client := &SearchClient{AccessToken: "test_token", URL: blocking.URL}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.FindUsersContext(ctx, SearchRequest{Limit: 1})
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrTimeout) || !strings.HasPrefix(err.Error(), "deadline exceeded for ") {
+		t.Errorf("Expected deadline exceeded error, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = client.FindUsersContext(ctx, SearchRequest{Limit: 1})
+	if !errors.Is(err, context.Canceled) || !strings.HasPrefix(err.Error(), "request canceled for ") {
+		t.Errorf("Expected canceled error, got %v", err)
+	}
+
+	// Сервер прекращает поиск по отменённому запросу
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	for _, q := range []SearchQuery{{}, {Query: "nosuchword"}} {
+		if _, err := filterAndSortUsers(ctx, newDataset(syntheticUsers(t, 10), time.Now()), q); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled from search for %q, got %v", q.Query, err)
+		}
+	}
+	r := httptest.NewRequest("GET", "/?limit=1", nil).WithContext(ctx)
+	r.Header.Set("AccessToken", "test_token")
+	w := httptest.NewRecorder()
+	SearchServer(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 for canceled request, got %d", w.Code)
+	}
+
+	// Оборванное тело ответа - ошибка запроса
+	truncated := NewSearchClient("test_token", "http://search.local/", WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(iotest.ErrReader(io.ErrUnexpectedEOF))}, nil
+	})))
+	if _, err = truncated.FindUsers(SearchRequest{Limit: 1}); !errors.Is(err, ErrRequestFailed) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected request error for truncated body, got %v", err)
+	}
+}
+
+// Транспорт из функции, чтобы проверять запросы клиента без сервера
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestNewSearchClient_Options(t *testing.T) {
+	var got *http.Request
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`[{"ID": 7}]`)),
+		}, nil
+	})
+
+	base := &http.Client{Timeout: time.Minute}
+	client := NewSearchClient("test_token", "http://search.local/", WithHTTPClient(base), WithTransport(transport),
+		WithTimeout(5*time.Second), WithMaxLimit(100), WithUserAgent("search-test/1.0"),
+		WithBaseHeaders(http.Header{"X-Trace": {"abc"}, "Accesstoken": {"spoofed"}}))
+
+	resp, err := client.FindUsers(SearchRequest{Limit: 80})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(resp.Users) != 1 || resp.Users[0].ID != 7 {
+		t.Errorf("Expected user 7 from transport, got %+v", resp.Users)
+	}
+	if limit := got.URL.Query().Get("limit"); limit != "81" {
+		t.Errorf("Expected limit 81 with MaxLimit 100, got %s", limit)
+	}
+	if got.Header.Get("User-Agent") != "search-test/1.0" || got.Header.Get("X-Trace") != "abc" || got.Header.Get("AccessToken") != "test_token" {
+		t.Errorf("Unexpected request headers: %v", got.Header)
+	}
+	if client.doer().Timeout != 5*time.Second || base.Timeout != time.Minute || base.Transport != nil {
+		t.Errorf("Expected options applied to a copy of the HTTP client")
+	}
+
+	// Без опций - прежние значения по умолчанию
+	plain := NewSearchClient("test_token", "http://search.local/")
+	if plain.doer() != (&SearchClient{}).doer() || plain.pageLimit() != 25 {
+		t.Errorf("Expected default client and page limit 25")
+	}
+	if _, err := NewSearchClient("test_token", "http://search.local/", WithTransport(transport)).FindUsers(SearchRequest{Limit: 80}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if limit := got.URL.Query().Get("limit"); limit != "26" {
+		t.Errorf("Expected default limit cap 26, got %s", limit)
+	}
+}
+
+func TestFindUsers_TypedErrors(t *testing.T) {
+	respond := func(status int, body string) ClientOption {
+		return WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}))
+	}
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	cases := []struct {
+		opt     ClientOption
+		req     SearchRequest
+		kind    error
+		status  int
+		code    string
+		message string
+	}{
+		{respond(401, ""), SearchRequest{}, ErrBadAccessToken, 401, "", "bad AccessToken"},
+		{respond(500, ""), SearchRequest{}, ErrServerFatal, 500, "", "SearchServer fatal error"},
+		{respond(400, `{"Error": "OrderField invalid"}`), SearchRequest{OrderField: "Bad"}, ErrInvalidOrderField, 400, ErrorBadOrderField, "OrderFeld Bad invalid"},
+		{respond(400, `{"Error": "invalid limit"}`), SearchRequest{}, ErrBadRequest, 400, "invalid limit", "unknown bad request error: invalid limit"},
+		{respond(400, `oops`), SearchRequest{}, ErrBadResponse, 400, "", "cant unpack error json: invalid character 'o' looking for beginning of value"},
+		{respond(200, `oops`), SearchRequest{}, ErrBadResponse, 200, "", "cant unpack result json: invalid character 'o' looking for beginning of value"},
+		{WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) { return nil, dialErr })), SearchRequest{}, ErrRequestFailed, 0, "", ""},
+		{nil, SearchRequest{Limit: -1}, ErrInvalidRequest, 0, "", "limit must be > 0"},
+		{nil, SearchRequest{Offset: -1}, ErrInvalidRequest, 0, "", "offset must be > 0"},
+		{nil, SearchRequest{Sort: []SortKey{{Field: "Age", Order: OrderByDesc}, {Field: "Name"}}}, ErrInvalidRequest, 0, "", "order of sort key Name must be OrderByAsc or OrderByDesc"},
+	}
+	for _, c := range cases {
+		var opts []ClientOption
+		if c.opt != nil {
+			opts = append(opts, c.opt)
+		}
+		_, err := NewSearchClient("test_token", "http://search.local/", opts...).FindUsers(c.req)
+		var searchErr *SearchError
+		if !errors.As(err, &searchErr) || !errors.Is(err, c.kind) {
+			t.Errorf("Expected *SearchError of kind %v, got %v", c.kind, err)
+			continue
+		}
+		if searchErr.StatusCode != c.status || searchErr.Code != c.code {
+			t.Errorf("%v: expected status %d and code %q, got %d and %q", c.kind, c.status, c.code, searchErr.StatusCode, searchErr.Code)
+		}
+		if c.message != "" && err.Error() != c.message {
+			t.Errorf("%v: expected message %q, got %q", c.kind, c.message, err.Error())
+		}
+		if c.status != 0 && searchErr.Params.Get("limit") != "1" {
+			t.Errorf("%v: expected request params in error, got %v", c.kind, searchErr.Params)
+		}
+	}
+
+	_, err := NewSearchClient("test_token", "http://search.local/", WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, dialErr
+	}))).FindUsers(SearchRequest{})
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr != dialErr || !strings.HasPrefix(err.Error(), "unknown error ") {
+		t.Errorf("Expected wrapped net.OpError, got %v", err)
+	}
+
+	if _, err := (&SearchClient{}).FindNextUsers(SearchRequest{}, &SearchResponse{}); err != ErrNoNextPage {
+		t.Errorf("Expected ErrNoNextPage, got %v", err)
+	}
+}
+
+// Контракт ошибок: настоящий FindUsers против настоящего SearchServer
+func TestFindUsers_ErrorContract(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer ts.Close()
+	broken := httptest.NewServer(NewSearchHandler(NewXMLFileStore("missing.xml"), SearchHandlerOptions{}))
+	defer broken.Close()
+
+	cases := []struct {
+		name    string
+		client  *SearchClient
+		req     SearchRequest
+		kind    error
+		status  int
+		code    string
+		param   string
+		message string
+	}{
+		{"order_field", NewSearchClient("test_token", ts.URL), SearchRequest{OrderField: "Phone"}, ErrInvalidOrderField, 400, ErrorBadOrderField, "order_field", "OrderFeld Phone invalid"},
+		{"sort", NewSearchClient("test_token", ts.URL), SearchRequest{Sort: []SortKey{{Field: "Phone", Order: OrderByAsc}}}, ErrInvalidOrderField, 400, ErrorBadOrderField, "order_field", "OrderFeld Phone:asc invalid"},
+		{"order_by", NewSearchClient("test_token", ts.URL), SearchRequest{OrderBy: 5}, ErrBadRequest, 400, ErrorBadOrderBy, "order_by", "unknown bad request error: invalid order_by: 5"},
+		{"query", NewSearchClient("test_token", ts.URL), SearchRequest{Query: "age:old"}, ErrBadRequest, 400, ErrorBadQuery, "query", `unknown bad request error: query syntax error at position 5: expected number for age but found "old"`},
+		{"match", NewSearchClient("test_token", ts.URL), SearchRequest{Match: "fuzzy"}, ErrBadRequest, 400, ErrorBadParam, "match", "unknown bad request error: invalid match: fuzzy"},
+		{"fields", NewSearchClient("test_token", ts.URL), SearchRequest{Fields: []string{"Password"}}, ErrBadRequest, 400, ErrorBadParam, "fields", `unknown bad request error: invalid fields: unknown field "Password"`},
+		{"filter", NewSearchClient("test_token", ts.URL), SearchRequest{Gender: "robot"}, ErrBadRequest, 400, ErrorBadParam, "gender", "unknown bad request error: invalid gender: robot"},
+		{"cursor", NewSearchClient("test_token", ts.URL), SearchRequest{Cursor: "garbage"}, ErrBadRequest, 400, ErrorBadCursor, "cursor", "unknown bad request error: invalid cursor"},
+		{"token", NewSearchClient("", ts.URL), SearchRequest{}, ErrBadAccessToken, 401, ErrorUnauthorized, "", "bad AccessToken"},
+		{"storage", NewSearchClient("test_token", broken.URL), SearchRequest{}, ErrServerFatal, 500, ErrorInternal, "", "SearchServer fatal error"},
+	}
+	for _, c := range cases {
+		_, err := c.client.FindUsers(c.req)
+		var searchErr *SearchError
+		if !errors.As(err, &searchErr) || !errors.Is(err, c.kind) {
+			t.Errorf("%s: expected *SearchError of kind %v, got %v", c.name, c.kind, err)
+			continue
+		}
+		if searchErr.StatusCode != c.status || searchErr.Code != c.code || searchErr.Param != c.param || err.Error() != c.message {
+			t.Errorf("%s: expected %d %q %q %q, got %d %q %q %q", c.name, c.status, c.code, c.param, c.message,
+				searchErr.StatusCode, searchErr.Code, searchErr.Param, err.Error())
+		}
+	}
+
+	// Ошибки, которые FindUsers отсекает сам, проверяются запросом напрямую
+	for target, expected := range map[string]SearchErrorResponse{
+		"/?limit=-1":          {Error: ErrorBadLimit, Param: "limit", Message: "invalid limit: -1"},
+		"/?offset=x":          {Error: ErrorBadOffset, Param: "offset", Message: "invalid offset: x"},
+		"/?offset=1&cursor=a": {Error: ErrorBadOffset, Param: "offset", Message: "invalid offset: cursor and offset are mutually exclusive"},
+	} {
+		req, _ := http.NewRequest("GET", ts.URL+target, nil) //nolint:errcheck
+		req.Header.Set("AccessToken", "test_token")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got SearchErrorResponse
+		err = json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != 400 || resp.Header.Get("Content-Type") != "application/json" || got != expected {
+			t.Errorf("%s: expected 400 JSON %+v, got %d %q %+v (%v)", target, expected, resp.StatusCode, resp.Header.Get("Content-Type"), got, err)
+		}
+	}
+
+	// Прочие статусы тоже разбираются
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleError(w, errors.New("Request canceled: context canceled"), http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	_, err := NewSearchClient("test_token", unavailable.URL).FindUsers(SearchRequest{})
+	var searchErr *SearchError
+	if !errors.As(err, &searchErr) || searchErr.Code != ErrorCanceled || err.Error() != "unexpected status 503: Request canceled: context canceled" {
+		t.Errorf("Expected 503 search error, got %v", err)
+	}
+}
+
+// Часы, которые не ждут, а только запоминают запрошенные паузы
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// Транспорт, отдающий ответы по очереди
+func scriptedTransport(t *testing.T, steps ...func() (*http.Response, error)) (ClientOption, *int) {
+	calls := 0
+	return WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if calls >= len(steps) {
+			t.Fatalf("unexpected request %d", calls+1)
+		}
+		calls++
+		return steps[calls-1]()
+	})), &calls
+}
+
+func statusResponse(status int, header http.Header, body string) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}
+}
+
+func TestSearchClient_Retry(t *testing.T) {
+	timeout := func() (*http.Response, error) { return nil, &net.DNSError{Err: "i/o timeout", IsTimeout: true} }
+	reset := func() (*http.Response, error) { return nil, errors.New("connection reset by peer") }
+
+	clock := &fakeClock{now: time.Now()}
+	var attempts []RetryAttempt
+	transport, calls := scriptedTransport(t,
+		statusResponse(500, nil, ""),
+		timeout,
+		reset,
+		statusResponse(429, http.Header{"Retry-After": {"1"}}, ""),
+		statusResponse(200, nil, `[{"ID": 1}]`),
+	)
+	client := NewSearchClient("test_token", "http://search.local/", transport, WithClock(clock), WithRetry(RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    time.Second,
+		Jitter:      0.5,
+		Rand:        func() float64 { return 0.5 },
+		OnAttempt:   func(a RetryAttempt) { attempts = append(attempts, a) },
+	}))
+	resp, err := client.FindUsers(SearchRequest{Limit: 1})
+	if err != nil || len(resp.Users) != 1 {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if *calls != 5 || len(attempts) != 4 {
+		t.Errorf("Expected 5 calls and 4 failed attempts, got %d and %d", *calls, len(attempts))
+	}
+	// Разброс 0.5*0.5 сокращает паузы на четверть; Retry-After берётся как есть
+	expected := []time.Duration{75 * time.Millisecond, 150 * time.Millisecond, 300 * time.Millisecond, time.Second}
+	if fmt.Sprint(clock.sleeps) != fmt.Sprint(expected) {
+		t.Errorf("Expected sleeps %v, got %v", expected, clock.sleeps)
+	}
+
+	cases := []struct {
+		name   string
+		steps  []func() (*http.Response, error)
+		policy RetryPolicy
+		ctx    time.Duration
+		calls  int
+		kind   error
+	}{
+		{"bad request", []func() (*http.Response, error){statusResponse(400, nil, `{"Error": "Query invalid"}`)}, RetryPolicy{MaxAttempts: 3}, 0, 1, ErrBadRequest},
+		{"429 without Retry-After", []func() (*http.Response, error){statusResponse(429, nil, "")}, RetryPolicy{MaxAttempts: 3}, 0, 1, ErrRequestFailed},
+		{"exhausted", []func() (*http.Response, error){statusResponse(503, nil, ""), statusResponse(502, nil, "")}, RetryPolicy{MaxAttempts: 2}, 0, 2, ErrRequestFailed},
+		{"deadline", []func() (*http.Response, error){statusResponse(500, nil, "")}, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute}, time.Second, 1, ErrServerFatal},
+	}
+	for _, c := range cases {
+		transport, calls := scriptedTransport(t, c.steps...)
+		client := NewSearchClient("test_token", "http://search.local/", transport, WithClock(&fakeClock{now: time.Now()}), WithRetry(c.policy))
+		ctx := context.Background()
+		if c.ctx > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.ctx)
+			defer cancel()
+		}
+		if _, err := client.FindUsersContext(ctx, SearchRequest{}); !errors.Is(err, c.kind) || *calls != c.calls {
+			t.Errorf("%s: expected %v after %d calls, got %v after %d", c.name, c.kind, c.calls, err, *calls)
+		}
+	}
+
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 3 * time.Second}.withDefaults()
+	for attempt, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 3 * time.Second, 40: 3 * time.Second} {
+		if got := policy.delay(attempt, errors.New("x")); got != expected {
+			t.Errorf("Attempt %d: expected delay %v, got %v", attempt, expected, got)
+		}
+	}
+	if got := policy.delay(1, &SearchError{RetryAfter: time.Hour}); got != time.Hour {
+		t.Errorf("Expected Retry-After delay as is, got %v", got)
+	}
+
+	// Сервер просит ждать дольше MaxDelay: повтора нет, ошибка несёт RetryAfter
+	transport, calls = scriptedTransport(t, statusResponse(503, http.Header{"Retry-After": {"3600"}}, ""))
+	var last RetryAttempt
+	client = NewSearchClient("test_token", "http://search.local/", transport, WithClock(&fakeClock{now: time.Now()}), WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		OnAttempt:   func(a RetryAttempt) { last = a },
+	}))
+	_, err = client.FindUsers(SearchRequest{})
+	var searchErr *SearchError
+	if !errors.As(err, &searchErr) || searchErr.RetryAfter != time.Hour || *calls != 1 {
+		t.Errorf("Expected error with Retry-After after one call, got %v after %d", err, *calls)
+	}
+	if last.Retry || last.Delay != time.Hour {
+		t.Errorf("Expected no retry after long Retry-After, got %+v", last)
+	}
+
+	// Отмена во время паузы по настоящим часам возвращает последнюю ошибку
+	transport, calls = scriptedTransport(t, statusResponse(500, nil, ""))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client = NewSearchClient("test_token", "http://search.local/", transport, WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Minute,
+		MaxDelay:    time.Hour,
+		OnAttempt:   func(RetryAttempt) { cancel() },
+	}))
+	if _, err := client.FindUsersContext(ctx, SearchRequest{}); !errors.Is(err, ErrServerFatal) || *calls != 1 {
+		t.Errorf("Expected ErrServerFatal after one call, got %v after %d", err, *calls)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for value, expected := range map[string]time.Duration{
+		"":                              0,
+		"2":                             2 * time.Second,
+		"-1":                            0,
+		"Fri, 02 Jan 2026 03:04:35 GMT": 30 * time.Second,
+		"Fri, 02 Jan 2026 03:00:00 GMT": 0,
+	} {
+		if got := parseRetryAfter(value, now); got != expected {
+			t.Errorf("Retry-After %q: expected %v, got %v", value, expected, got)
+		}
+	}
+}
+
+func TestSearchClient_CircuitBreaker(t *testing.T) {
+	var status atomic.Int32
+	var calls atomic.Int32
+	release := make(chan struct{})
+	blocked := make(chan struct{})
+	transport := WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		if status.Load() == 0 {
+			// Пробный запрос ждёт, пока тест не проверит остальные вызовы
+			close(blocked)
+			<-release
+			return statusResponse(500, nil, "")()
+		}
+		return statusResponse(int(status.Load()), nil, `[{"ID": 1}]`)()
+	}))
+
+	clock := &fakeClock{now: time.Now()}
+	var mu sync.Mutex
+	var transitions []string
+	client := NewSearchClient("test_token", "http://search.local/", transport, WithClock(clock), WithCircuitBreaker(BreakerSettings{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		CoolDown:     10 * time.Second,
+		OnStateChange: func(from, to BreakerState) {
+			mu.Lock()
+			transitions = append(transitions, from.String()+"->"+to.String())
+			mu.Unlock()
+		},
+	}))
+	findConcurrently := func(n int) []error {
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = client.FindUsers(SearchRequest{})
+			}()
+		}
+		wg.Wait()
+		return errs
+	}
+
+	// Ошибки самого запроса сбоями не считаются
+	status.Store(400)
+	findConcurrently(4)
+	status.Store(500)
+	for _, err := range findConcurrently(4) {
+		if !errors.Is(err, ErrServerFatal) {
+			t.Errorf("Expected server error before the circuit opens, got %v", err)
+		}
+	}
+	if client.BreakerState() != BreakerOpen {
+		t.Fatalf("Expected open circuit, got %v", client.BreakerState())
+	}
+	for _, err := range findConcurrently(16) {
+		var searchErr *SearchError
+		if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &searchErr) {
+			t.Errorf("Expected *SearchError with ErrCircuitOpen, got %v", err)
+		}
+	}
+	if calls.Load() != 8 {
+		t.Errorf("Expected open circuit to fail fast, got %d requests", calls.Load())
+	}
+
+	// После охлаждения проходит один пробный запрос, остальные отклоняются
+	clock.After(10 * time.Second)
+	status.Store(0)
+	probe := make(chan error)
+	go func() {
+		_, err := client.FindUsers(SearchRequest{})
+		probe <- err
+	}()
+	<-blocked
+	if _, err := client.FindUsers(SearchRequest{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected concurrent call in half-open state to fail fast, got %v", err)
+	}
+	close(release)
+	if err := <-probe; !errors.Is(err, ErrServerFatal) || client.BreakerState() != BreakerOpen {
+		t.Errorf("Expected failed probe to reopen the circuit, got %v and %v", err, client.BreakerState())
+	}
+
+	clock.After(10 * time.Second)
+	status.Store(200)
+	if _, err := client.FindUsers(SearchRequest{}); err != nil || client.BreakerState() != BreakerClosed {
+		t.Errorf("Expected successful probe to close the circuit, got %v and %v", err, client.BreakerState())
+	}
+	expected := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if fmt.Sprint(transitions) != fmt.Sprint(expected) {
+		t.Errorf("Expected transitions %v, got %v", expected, transitions)
+	}
+
+	// Отмена вызывающим не считается сбоем и освобождает пробный слот
+	breaker := newCircuitBreaker(BreakerSettings{MinRequests: 1, Interval: time.Second}, clock)
+	generation, _ := breaker.allow()
+	breaker.done(generation, breakerIgnore)
+	generation, _ = breaker.allow()
+	clock.After(time.Second)
+	breaker.done(generation, breakerFailure)
+	if breaker.currentState() != BreakerClosed {
+		t.Errorf("Expected failures from the previous window to be ignored, got %v", breaker.currentState())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled := WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, r.Context().Err()
+	}))
+	defaults := NewSearchClient("test_token", "http://search.local/", cancelled, WithCircuitBreaker(BreakerSettings{}))
+	if _, err := defaults.FindUsersContext(ctx, SearchRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if defaults.breaker.requests != 0 || defaults.breaker.settings.MinRequests != 10 {
+		t.Errorf("Expected cancelled call not to be counted with default settings, got %d of %d",
+			defaults.breaker.requests, defaults.breaker.settings.MinRequests)
+	}
+	if (&SearchClient{}).BreakerState() != BreakerClosed {
+		t.Error("Expected client without breaker to report closed state")
+	}
+}
+
+// Хранилище без Version: 304 отдаётся только после поиска
+type unversionedStore struct {
+	UserStore
+}
+
+func TestSearchServer_ETag(t *testing.T) {
+	users := []UserServer{{ID: 1, Name: "Boyd", Age: 22}, {ID: 2, Name: "Hilda", Age: 21}}
+	for _, store := range []UserStore{NewMemoryStore(users), unversionedStore{NewMemoryStore(users)}} {
+		handler := NewSearchHandler(store, SearchHandlerOptions{})
+		get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest("GET", target, nil)
+			r.Header.Set("AccessToken", "token")
+			if ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			return w
+		}
+
+		first := get("/?limit=1&order_field=Age", "")
+		etag := first.Header().Get("ETag")
+		if first.Code != 200 || etag == "" {
+			t.Fatalf("%T: expected 200 with ETag, got %d %q", store, first.Code, etag)
+		}
+		if again := get("/?order_field=Age&limit=1", ""); again.Header().Get("ETag") != etag {
+			t.Errorf("%T: expected ETag not to depend on parameter order", store)
+		}
+		for _, match := range []string{etag, `"other", W/` + etag, "*"} {
+			w := get("/?limit=1&order_field=Age", match)
+			if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+				t.Errorf("%T: expected empty 304 for If-None-Match %s, got %d %q", store, match, w.Code, w.Body.String())
+			}
+		}
+		if w := get("/?limit=2&order_field=Age", etag); w.Code != 200 || w.Header().Get("ETag") == etag {
+			t.Errorf("%T: expected other parameters to get a new ETag, got %d", store, w.Code)
+		}
+	}
+
+	other := NewSearchHandler(NewMemoryStore(users[:1]), SearchHandlerOptions{})
+	r := httptest.NewRequest("GET", "/?limit=1&order_field=Age", nil)
+	r.Header.Set("AccessToken", "token")
+	w := httptest.NewRecorder()
+	other.ServeHTTP(w, r)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	NewSearchHandler(NewMemoryStore(users), SearchHandlerOptions{}).ServeHTTP(w, r)
+	if w.Code != 200 {
+		t.Errorf("Expected another dataset version to invalidate ETag, got %d", w.Code)
+	}
+}
+
+// Обработчик с файловым хранилищем отвечает 304 по версии снимка, не
+// выполняя поиск: запрос с ошибкой в query иначе получил бы 400
+func TestSearchServer_ETagFileStore(t *testing.T) {
+	get := func(handler http.Handler, target, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", target, nil)
+		r.Header.Set("AccessToken", "token")
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	first := get(testHandler, "/?limit=1&order_field=Age", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != 200 || etag == "" {
+		t.Fatalf("Expected 200 with ETag, got %d %q", first.Code, etag)
+	}
+	if w := get(testHandler, "/?limit=1&order_field=Age", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected empty 304, got %d %q", w.Code, w.Body.String())
+	}
+
+	const target = "/?limit=1&query=age:old"
+	if w := get(testHandler, target, ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected search for %s to fail with 400, got %d", target, w.Code)
+	}
+	version, err := testStore.Version(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r := httptest.NewRequest("GET", target, nil)
+	params, err := validateParams(r)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	params.query.Match = testHandler.opts.DefaultMatch
+	for _, match := range []string{responseETag(version, r.URL.Query(), params), "*"} {
+		if w := get(testHandler, target, match); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("Expected 304 without search for If-None-Match %s, got %d %q", match, w.Code, w.Body.String())
+		}
+	}
+
+	// Без версии снимка обработчик не отвечает 304 и сообщает об ошибке поиска
+	missing := NewSearchHandler(NewXMLFileStore("missing.xml"), SearchHandlerOptions{})
+	if w := get(missing, "/?limit=1", "*"); w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for missing dataset, got %d", w.Code)
+	}
+}
+
+func TestSearchClient_Cache(t *testing.T) {
+	var handler atomic.Pointer[SearchHandler]
+	handler.Store(NewSearchHandler(NewMemoryStore([]UserServer{{ID: 1, Name: "Boyd"}}), SearchHandlerOptions{}))
+	var requests, notModified atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		rec := httptest.NewRecorder()
+		handler.Load().ServeHTTP(rec, r)
+		if rec.Code == http.StatusNotModified {
+			notModified.Add(1)
+		}
+		for name, values := range rec.Header() {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes()) //nolint:errcheck
+	}))
+	defer ts.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	cache := NewLRUCache(2)
+	client := NewSearchClient("test_token", ts.URL, WithClock(clock), WithCache(cache, time.Minute))
+	req := SearchRequest{Limit: 5, OrderField: "Id"}
+
+	first, err := client.FindUsers(req)
+	if err != nil || len(first.Users) != 1 {
+		t.Fatalf("Expected one user, got %v", err)
+	}
+	first.Users[0].Name = "changed by caller"
+	cached, err := client.FindUsers(req)
+	if err != nil || requests.Load() != 1 || cached.Users[0].Name != "Boyd" {
+		t.Errorf("Expected fresh response from cache, got %d requests, %v, %v", requests.Load(), cached, err)
+	}
+
+	clock.After(time.Minute)
+	revalidated, err := client.FindUsers(req)
+	if err != nil || requests.Load() != 2 || notModified.Load() != 1 || revalidated.Users[0].ID != 1 {
+		t.Errorf("Expected 304 revalidation, got %d requests, %d not modified, %v", requests.Load(), notModified.Load(), err)
+	}
+	if _, err := client.FindUsers(req); err != nil || requests.Load() != 2 {
+		t.Errorf("Expected revalidated response to be fresh again, got %d requests", requests.Load())
+	}
+	// Клиент с другим токеном не получает чужой ответ из общего кэша
+	other := NewSearchClient("other_token", ts.URL, WithClock(clock), WithCache(cache, time.Minute))
+	if _, err := other.FindUsers(req); err != nil || requests.Load() != 3 {
+		t.Errorf("Expected separate cache entry for another token, got %d requests, %v", requests.Load(), err)
+	}
+
+	clock.After(time.Minute)
+	handler.Store(NewSearchHandler(NewMemoryStore([]UserServer{{ID: 1, Name: "Boyd"}, {ID: 2, Name: "Hilda"}}), SearchHandlerOptions{}))
+	changed, err := client.FindUsers(req)
+	if err != nil || len(changed.Users) != 2 || changed.DatasetVersion == first.DatasetVersion {
+		t.Errorf("Expected new dataset after revalidation, got %v, %v", changed, err)
+	}
+
+	client.FindUsers(SearchRequest{Limit: 1}) //nolint:errcheck
+	client.FindUsers(SearchRequest{Limit: 2}) //nolint:errcheck
+	if _, ok := cache.Get(client.cacheKey(mustParams(t, client, req))); ok || cache.Len() != 2 {
+		t.Errorf("Expected least recently used response to be evicted, got %d entries", cache.Len())
+	}
+
+	// Без ETag ответ только хранится до истечения ttl
+	transport, calls := scriptedTransport(t, statusResponse(200, nil, `[]`), statusResponse(200, nil, `[]`), statusResponse(304, nil, ""))
+	plain := NewSearchClient("test_token", "http://search.local/", transport, WithClock(clock), WithCache(NewLRUCache(0), time.Second))
+	plain.FindUsers(SearchRequest{}) //nolint:errcheck
+	clock.After(time.Second)
+	plain.FindUsers(SearchRequest{}) //nolint:errcheck
+	if *calls != 2 {
+		t.Errorf("Expected expired response without ETag to be fetched again, got %d calls", *calls)
+	}
+	plain.cache.Set(plain.cacheKey(mustParams(t, plain, SearchRequest{})), &CachedResponse{Response: &SearchResponse{}})
+	if _, err := plain.FindUsers(SearchRequest{}); !errors.Is(err, ErrRequestFailed) {
+		t.Errorf("Expected 304 without a validator to fail, got %v", err)
+	}
+}
+
+func mustParams(t *testing.T, client *SearchClient, req SearchRequest) url.Values {
+	_, params, err := client.requestParams(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return params
+}
+
+func TestSearchClient_Deduplication(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	aborted := make(chan error, 1)
+	transport := WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		started <- struct{}{}
+		select {
+		case <-release:
+			return statusResponse(200, nil, `[{"ID": 1, "Name": "Boyd"}]`)()
+		case <-r.Context().Done():
+			aborted <- r.Context().Err()
+			return nil, r.Context().Err()
+		}
+	}))
+	client := NewSearchClient("test_token", "http://search.local/", transport, WithDeduplication())
+	req := SearchRequest{Limit: 1}
+	key := "http://search.local/\x00test_token\x00" + mustParams(t, client, req).Encode()
+	waitFor := func(waiters int) {
+		for {
+			client.flights.mu.Lock()
+			c := client.flights.calls[key]
+			joined := c != nil && c.waiters == waiters
+			client.flights.mu.Unlock()
+			if joined {
+				return
+			}
+			runtime.Gosched()
+		}
+	}
+
+	results := make([]*SearchResponse, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if results[i], err = client.FindUsers(req); err != nil {
+				t.Errorf("Expected shared result, got %v", err)
+			}
+		}()
+	}
+	waitFor(len(results))
+	release <- struct{}{}
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("Expected one HTTP request for identical calls, got %d", calls.Load())
+	}
+	results[0].Users[0].Name = "changed by caller"
+	if results[1].Users[0].Name != "Boyd" {
+		t.Error("Expected every caller to get its own copy of the response")
+	}
+
+	// Отмена одного вызова не прерывает общий запрос, пока его ждут другие
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := client.FindUsersContext(ctx, req)
+		canceled <- err
+	}()
+	<-started
+	<-started
+	waitFor(1)
+	done := make(chan error)
+	go func() {
+		_, err := client.FindUsers(req)
+		done <- err
+	}()
+	waitFor(2)
+	cancel()
+	if err := <-canceled; !errors.Is(err, ErrRequestFailed) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected canceled caller to get its own error, got %v", err)
+	}
+	release <- struct{}{}
+	if err := <-done; err != nil || calls.Load() != 2 {
+		t.Errorf("Expected remaining caller to get the shared result, got %v after %d calls", err, calls.Load())
+	}
+
+	// Когда уходят все, общий запрос отменяется
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := client.FindUsersContext(ctx, req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected canceled error, got %v", err)
+	}
+	if err := <-aborted; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected abandoned shared request to be canceled, got %v", err)
+	}
+
+	// Ошибка общего запроса достаётся вызвавшему как есть
+	failing := NewSearchClient("test_token", "http://search.local/", WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return statusResponse(500, nil, "")()
+	})), WithDeduplication())
+	if _, err := failing.FindUsers(req); !errors.Is(err, ErrServerFatal) {
+		t.Errorf("Expected ErrServerFatal from shared request, got %v", err)
+	}
+}
+
+func TestSearchClient_Endpoints(t *testing.T) {
+	var hits [3]atomic.Int32
+	block := make(chan struct{})
+	servers := make([]*httptest.Server, len(hits))
+	urls := make([]string, len(hits))
+	for i := range servers {
+		servers[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[i].Add(1)
+			if r.FormValue("query") == "block" {
+				<-block
+			}
+			testHandler.ServeHTTP(w, r)
+		}))
+		defer servers[i].Close()
+		urls[i] = servers[i].URL
+	}
+	counts := func() string {
+		return fmt.Sprint(hits[0].Load(), hits[1].Load(), hits[2].Load())
+	}
+	clock := &fakeClock{now: time.Now()}
+	client := NewSearchClient("test_token", "", WithClock(clock), WithEndpoints(urls, EndpointPolicy{MaxFailures: 2, EjectFor: time.Minute}))
+	for range 6 {
+		if _, err := client.FindUsers(SearchRequest{Limit: 1}); err != nil {
+			t.Fatalf("Expected success, got %v", err)
+		}
+	}
+	if counts() != "2 2 2" {
+		t.Errorf("Expected round-robin distribution, got %s", counts())
+	}
+
+	// Остановленная реплика: запросы уходят на соседние, после двух сбоев подряд она выводится из работы
+	servers[1].Close()
+	for range 6 {
+		if _, err := client.FindUsers(SearchRequest{Limit: 1}); err != nil {
+			t.Fatalf("Expected failover to a live endpoint, got %v", err)
+		}
+	}
+	ejected := client.endpoints.endpoints[1]
+	if ejected.failures != 2 || !clock.Now().Before(ejected.ejectedUntil) {
+		t.Errorf("Expected dead endpoint to be ejected, got %d failures", ejected.failures)
+	}
+	if counts() != "5 2 5" {
+		t.Errorf("Expected live endpoints to share the load, got %s", counts())
+	}
+
+	// После EjectFor реплика снова пробуется и после первого же сбоя выводится опять
+	clock.After(time.Minute)
+	for range 3 {
+		client.FindUsers(SearchRequest{Limit: 1}) //nolint:errcheck
+	}
+	if ejected.failures != 3 || !clock.Now().Before(ejected.ejectedUntil) {
+		t.Errorf("Expected failed probe to eject endpoint again, got %d failures", ejected.failures)
+	}
+
+	// Пока первая реплика занята, запросы идут на вторую
+	before := [2]int32{hits[0].Load(), hits[2].Load()}
+	least := NewSearchClient("test_token", "", WithClock(clock), WithEndpoints([]string{urls[0], urls[2]}, EndpointPolicy{Balance: BalanceLeastOutstanding}))
+	blocked := make(chan error)
+	go func() {
+		_, err := least.FindUsers(SearchRequest{Limit: 1, Query: "block"})
+		blocked <- err
+	}()
+	for hits[0].Load() == before[0] {
+		runtime.Gosched()
+	}
+	for range 3 {
+		if _, err := least.FindUsers(SearchRequest{Limit: 1}); err != nil {
+			t.Fatalf("Expected success, got %v", err)
+		}
+	}
+	close(block)
+	if err := <-blocked; err != nil || hits[0].Load() != before[0]+1 || hits[2].Load() != before[1]+3 {
+		t.Errorf("Expected requests to avoid the busy endpoint, got %v and %s", err, counts())
+	}
+
+	// Когда выведены все реплики, запрос всё равно пробует ту, что вернётся раньше
+	servers[0].Close()
+	servers[2].Close()
+	if _, err := client.FindUsers(SearchRequest{Limit: 1}); !errors.Is(err, ErrRequestFailed) {
+		t.Errorf("Expected error when every endpoint is down, got %v", err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	broken := NewSearchClient("test_token", "", WithEndpoints([]string{failing.URL, "http://bad host"}, EndpointPolicy{MaxFailures: 1}))
+	if _, err := broken.FindUsers(SearchRequest{}); !errors.Is(err, ErrRequestFailed) || broken.endpoints.endpoints[0].ejectedUntil.IsZero() {
+		t.Errorf("Expected 5xx to count as endpoint failure, got %v", err)
+	}
+	if _, err := broken.FindUsers(SearchRequest{}); !errors.Is(err, ErrRequestFailed) {
+		t.Errorf("Expected invalid endpoint URL to fail, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := broken.FindUsersContext(ctx, SearchRequest{}); !errors.Is(err, context.Canceled) || broken.endpoints.endpoints[1].outstanding != 0 {
+		t.Errorf("Expected canceled request to release the endpoint, got %v", err)
+	}
+}
+
+// Часы, у которых срок хеджирования наступает по команде теста
+type manualClock struct {
+	*fakeClock
+	after chan time.Time
+}
+
+func (c manualClock) After(d time.Duration) <-chan time.Time {
+	return c.after
+}
+
+// Тело ответа, которое сообщает о своём закрытии
+type closeNotifyBody struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (b closeNotifyBody) Close() error {
+	close(b.closed)
+	return nil
+}
+
+func TestSearchClient_Hedging(t *testing.T) {
+	arrived := make(chan struct{}, 4)
+	canceled := make(chan struct{}, 4)
+	// Медленная реплика отвечает только после отмены запроса клиентом
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-r.Context().Done()
+		canceled <- struct{}{}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(SearchServer))
+	defer fast.Close()
+
+	clock := manualClock{&fakeClock{now: time.Now()}, make(chan time.Time, 1)}
+	// Срок хеджирования наступает, когда первый запрос дошёл до сервера
+	fireOnArrival := func() {
+		go func() {
+			<-arrived
+			clock.after <- time.Now()
+		}()
+	}
+	client := NewSearchClient("test_token", "", WithClock(clock), WithHedging(HedgePolicy{}),
+		WithEndpoints([]string{slow.URL, fast.URL}, EndpointPolicy{}))
+	if client.hedge.policy.Delay != 100*time.Millisecond {
+		t.Errorf("Expected default hedge delay, got %v", client.hedge.policy.Delay)
+	}
+
+	// Первый запрос уходит на медленную реплику, второй отвечает и выигрывает
+	fireOnArrival()
+	if resp, err := client.FindUsers(SearchRequest{Limit: 1}); err != nil || len(resp.Users) != 1 {
+		t.Fatalf("Expected hedged request to succeed, got %v", err)
+	}
+	<-canceled
+	if stats := client.HedgeStats(); stats != (HedgeStats{Requests: 1, Fired: 1, Won: 1}) {
+		t.Errorf("Expected one fired and won hedge, got %+v", stats)
+	}
+
+	// Быстрая реплика отвечает до срока, второй запрос не нужен
+	fastFirst := NewSearchClient("test_token", "", WithClock(clock), WithHedging(HedgePolicy{}),
+		WithEndpoints([]string{fast.URL, slow.URL}, EndpointPolicy{}))
+	if _, err := fastFirst.FindUsers(SearchRequest{Limit: 1}); err != nil {
+		t.Fatalf("Expected primary request to succeed, got %v", err)
+	}
+	if stats := fastFirst.HedgeStats(); stats != (HedgeStats{Requests: 1}) {
+		t.Errorf("Expected no hedge, got %+v", stats)
+	}
+
+	// Второй запрос отправлен, но первый отвечает раньше: второй отменяется
+	var requests atomic.Int32
+	hedgeArrived := make(chan struct{})
+	single := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			arrived <- struct{}{}
+			<-hedgeArrived
+			SearchServer(w, r)
+			return
+		}
+		close(hedgeArrived)
+		<-r.Context().Done()
+		canceled <- struct{}{}
+	}))
+	defer single.Close()
+	primary := NewSearchClient("test_token", single.URL, WithClock(clock), WithHedging(HedgePolicy{Delay: time.Second}))
+	fireOnArrival()
+	if _, err := primary.FindUsers(SearchRequest{Limit: 1}); err != nil {
+		t.Fatalf("Expected primary request to succeed, got %v", err)
+	}
+	<-canceled
+	if stats := primary.HedgeStats(); stats != (HedgeStats{Requests: 1, Fired: 1, Won: 0}) {
+		t.Errorf("Expected fired hedge to lose, got %+v", stats)
+	}
+
+	// Ошибка до срока возвращается сразу
+	fast.Close()
+	down := NewSearchClient("test_token", fast.URL, WithClock(clock), WithHedging(HedgePolicy{}))
+	if _, err := down.FindUsers(SearchRequest{}); !errors.Is(err, ErrRequestFailed) || down.HedgeStats().Fired != 0 {
+		t.Errorf("Expected connection error without hedge, got %v", err)
+	}
+
+	// Ошибка второго запроса ждёт ответа первого
+	hedgeFailed := make(chan struct{})
+	release := make(chan struct{})
+	gated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		SearchServer(w, r)
+	}))
+	defer gated.Close()
+	deadHost := strings.TrimPrefix(fast.URL, "http://")
+	watchDead := WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil && r.URL.Host == deadHost {
+			// контекст второго запроса отменяется, когда его ошибка обработана
+			go func() {
+				<-r.Context().Done()
+				close(hedgeFailed)
+			}()
+		}
+		return resp, err
+	}))
+	flaky := NewSearchClient("test_token", "", WithClock(clock), WithHedging(HedgePolicy{}), watchDead,
+		WithEndpoints([]string{gated.URL, fast.URL}, EndpointPolicy{}))
+	fireOnArrival()
+	go func() {
+		<-hedgeFailed
+		close(release)
+	}()
+	if resp, err := flaky.FindUsers(SearchRequest{Limit: 1}); err != nil || len(resp.Users) != 1 {
+		t.Fatalf("Expected primary response after failed hedge, got %v", err)
+	}
+	if stats := flaky.HedgeStats(); stats != (HedgeStats{Requests: 1, Fired: 1}) {
+		t.Errorf("Expected fired hedge to fail, got %+v", stats)
+	}
+
+	// Второму запросу некуда идти: он не отправляется и не считается, а
+	// после отмены возвращается ошибка первого
+	onlySlow := NewSearchClient("test_token", "", WithClock(clock), WithHedging(HedgePolicy{}), WithEndpoints([]string{slow.URL}, EndpointPolicy{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-arrived
+		clock.after <- time.Now()
+		// отмена - только после того, как срок хеджирования обработан
+		for len(clock.after) > 0 {
+			runtime.Gosched()
+		}
+		cancel()
+	}()
+	if _, err := onlySlow.FindUsersContext(ctx, SearchRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected canceled hedged request, got %v", err)
+	}
+	if stats := onlySlow.HedgeStats(); stats != (HedgeStats{Requests: 1}) {
+		t.Errorf("Expected no hedge without an untried endpoint, got %+v", stats)
+	}
+
+	// Ответ, пришедший после победителя, закрывается
+	lateClosed := make(chan struct{})
+	var sent atomic.Int32
+	late := NewSearchClient("test_token", "http://search.local/", WithClock(clock), WithHedging(HedgePolicy{}),
+		WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if sent.Add(1) == 1 {
+				arrived <- struct{}{}
+				<-r.Context().Done()
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{},
+					Body: closeNotifyBody{strings.NewReader(`[]`), lateClosed}}, nil
+			}
+			return statusResponse(200, nil, `[{"ID": 1}]`)()
+		})))
+	fireOnArrival()
+	if resp, err := late.FindUsers(SearchRequest{Limit: 1}); err != nil || len(resp.Users) != 1 {
+		t.Fatalf("Expected hedged response, got %v", err)
+	}
+	<-lateClosed
+	if stats := late.HedgeStats(); stats != (HedgeStats{Requests: 1, Fired: 1, Won: 1}) {
+		t.Errorf("Expected one fired and won hedge, got %+v", stats)
+	}
+
+	if (&SearchClient{}).HedgeStats() != (HedgeStats{}) {
+		t.Error("Expected zero stats without hedging")
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+var (
+	errInvalidCursor = errors.New("invalid cursor")
+	errStaleCursor   = errors.New("stale cursor: dataset changed since the cursor was issued")
+)
+
+// Содержимое курсора следующей страницы. Позиция задаётся значениями ключей
+// сортировки и ID последнего пользователя страницы: следующая страница
+// находится двоичным поиском по ним. Курсор действителен, только пока
+// версия снимка не изменилась
+type searchCursor struct {
+	Version string            `json:"v"`           // версия снимка
+	Query   string            `json:"q"`           // хеш параметров поиска, см. queryHash
+	ID      int               `json:"id"`          // последний пользователь выданной страницы
+	Keys    []json.RawMessage `json:"k,omitempty"` // его значения ключей сортировки по порядку
+}
+
+// Курсор вида base64(JSON).base64(HMAC-SHA256): клиент не может ни
+// подделать его, ни изменить незаметно для сервера
+func encodeCursor(secret []byte, c searchCursor) string {
+	payload, _ := json.Marshal(c) //nolint:errcheck
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signCursor(secret, payload))
+}
+
+func decodeCursor(secret []byte, value string) (searchCursor, error) {
+	var c searchCursor
+	encoded, encodedSig, ok := strings.Cut(value, ".")
+	if !ok {
+		return c, errInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return c, errInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, signCursor(secret, payload)) {
+		return c, errInvalidCursor
+	}
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return c, errInvalidCursor
+	}
+	return c, nil
+}
+
+func signCursor(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload) //nolint:errcheck
+	return mac.Sum(nil)
+}
+
+// Хеш параметров, от которых зависят состав и порядок результата;
+// курсор нельзя продолжить с другим запросом
+func queryHash(q SearchQuery) string {
+	hash := fnv.New64a()
+	json.NewEncoder(hash).Encode(q) //nolint:errcheck
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Смещение первой строки после позиции курсора
+func cursorOffset(secret []byte, value string, q SearchQuery, result *SearchResult) (int, error) {
+	c, err := decodeCursor(secret, value)
+	if err != nil {
+		return 0, err
+	}
+	if c.Query != queryHash(q) {
+		return 0, fmt.Errorf("%w: query parameters differ from the original request", errInvalidCursor)
+	}
+	if c.Version != result.Version {
+		return 0, errStaleCursor
+	}
+	keys := q.sortKeys()
+	if len(c.Keys) != len(keys) {
+		return 0, errInvalidCursor
+	}
+	if len(keys) > 0 {
+		after := UserServer{ID: c.ID}
+		for n, key := range keys {
+			if err := json.Unmarshal(c.Keys[n], sortKeyValue(key.Field, &after)); err != nil {
+				return 0, errInvalidCursor
+			}
+		}
+		return sort.Search(len(result.Users), func(i int) bool {
+			return compareUsersByKeys(keys, collations[q.Locale], &result.Users[i], &after) > 0
+		}), nil
+	}
+	// Без ключей сортировки пользователи идут в порядке хранилища, и
+	// позицию приходится искать по ID
+	for i := range result.Users {
+		if result.Users[i].ID == c.ID {
+			return i + 1, nil
+		}
+	}
+	return 0, errInvalidCursor
+}
+
+// Курсор после последней строки страницы page, начинающейся с offset, или
+// пустая строка, если дальше ничего нет. При lookahead последняя строка полной
+// страницы - лишняя, по ней клиент узнаёт о следующей странице, и курсор
+// ставится перед ней
+func nextCursor(secret []byte, q SearchQuery, result *SearchResult, page []UserServer, offset, limit int, lookahead bool) string {
+	last := len(page) - 1
+	if lookahead && len(page) == limit {
+		last--
+	}
+	if last < 0 || offset+last+1 >= len(result.Users) {
+		return ""
+	}
+	c := searchCursor{Version: result.Version, Query: queryHash(q), ID: page[last].ID}
+	for _, key := range q.sortKeys() {
+		value, _ := json.Marshal(sortKeyValue(key.Field, &page[last])) //nolint:errcheck
+		c.Keys = append(c.Keys, value)
+	}
+	return encodeCursor(secret, c)
+}
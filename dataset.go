@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Неизменяемый снимок набора данных. После построения не модифицируется,
+// поэтому может читаться из любого числа горутин без блокировок
+type dataset struct {
+	users      []UserServer
+	searchable map[string][]searchFields // режим сравнения -> поля для поиска по индексам users
+	sorted     map[viewKey][]int         // порядок сортировки -> индексы users в этом порядке
+	rank       map[viewKey][]int         // порядок сортировки -> позиция каждого пользователя в sorted
+	collation  map[string][]string       // строковое поле сортировки -> ключи сопоставления по индексам users
+	index      map[string]*invertedIndex // режим сравнения -> индекс
+	byID       map[int]int               // ID -> индекс в users
+	loadedAt   time.Time
+	version    string    // хеш содержимого файла, из которого построен снимок
+	stamp      fileStamp // состояние файла на момент загрузки
+}
+
+// Порядок отсортированного представления. Все представления строгие:
+// при равных значениях поля пользователи идут по возрастанию ID
+type viewKey struct {
+	field string
+	order int // OrderByAsc или OrderByDesc; для OrderByAsIs поле не важно
+}
+
+func newViewKey(field string, order int) viewKey {
+	if order == OrderByAsIs {
+		return viewKey{order: OrderByAsIs}
+	}
+	return viewKey{field: field, order: order}
+}
+
+// Name и About, заранее преобразованные для режима сравнения
+type searchFields struct {
+	name  string
+	about string
+}
+
+// Построение снимка из списка пользователей
+func newDataset(users []UserServer, loadedAt time.Time) *dataset {
+	ds := &dataset{
+		users:      users,
+		searchable: make(map[string][]searchFields, len(matchModes)),
+		sorted:     make(map[viewKey][]int, 2*len(sortFields)+1),
+		rank:       make(map[viewKey][]int, 2*len(sortFields)+1),
+		collation:  make(map[string][]string),
+		index:      make(map[string]*invertedIndex, len(matchModes)),
+		byID:       make(map[int]int, len(users)),
+		loadedAt:   loadedAt,
+	}
+	for i, u := range users {
+		ds.byID[u.ID] = i
+	}
+	for _, mode := range matchModes {
+		transform := matchTransforms[mode]
+		fields := make([]searchFields, len(users))
+		for i := range users {
+			fields[i] = searchFields{
+				name:  transform(users[i].Name),
+				about: transform(users[i].About),
+			}
+		}
+		ds.searchable[mode] = fields
+		ds.index[mode] = newInvertedIndex(users, transform)
+	}
+	for _, f := range sortFields {
+		if f.text == nil {
+			continue
+		}
+		keys := make([]string, len(users))
+		for i := range users {
+			keys[i] = collationKey(f.text(&users[i]))
+		}
+		ds.collation[f.name] = keys
+	}
+	ds.addView(newViewKey("", OrderByAsIs))
+	for _, f := range sortFields {
+		ds.addView(newViewKey(f.name, OrderByAsc))
+		ds.addView(newViewKey(f.name, OrderByDesc))
+	}
+	return ds
+}
+
+func (ds *dataset) addView(key viewKey) {
+	view := ds.buildSortedView(key)
+	rank := make([]int, len(view))
+	for pos, i := range view {
+		rank[i] = pos
+	}
+	ds.sorted[key] = view
+	ds.rank[key] = rank
+}
+
+// Индексы пользователей в порядке key; при равенстве поля - по возрастанию ID.
+// OrderByAsIs - порядок набора данных
+func (ds *dataset) buildSortedView(key viewKey) []int {
+	view := make([]int, len(ds.users))
+	for i := range view {
+		view[i] = i
+	}
+	if key.order == OrderByAsIs {
+		return view
+	}
+	compare := ds.comparator(key.field)
+	sort.Slice(view, func(i, j int) bool {
+		if c := compare(view[i], view[j]); c != 0 {
+			return c*key.order < 0
+		}
+		return ds.users[view[i]].ID < ds.users[view[j]].ID
+	})
+	return view
+}
+
+// Чтение и разбор XML-файла в новый снимок
+func loadDataset(path string) (*dataset, error) {
+	xmlFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset file: %w", err)
+	}
+	defer xmlFile.Close()
+
+	info, err := xmlFile.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat dataset file: %w", err)
+	}
+
+	// Хеш считается попутно с разбором, файл читается один раз
+	hash := fnv.New64a()
+	var data UsersXML
+	if err := xml.NewDecoder(io.TeeReader(xmlFile, hash)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode XML: %w", err)
+	}
+	if err := validateUsersXML(data.Users); err != nil {
+		return nil, fmt.Errorf("invalid dataset: %w", err)
+	}
+
+	users := make([]UserServer, len(data.Users))
+	for i, u := range data.Users {
+		if users[i], err = userFromXML(u); err != nil {
+			return nil, fmt.Errorf("invalid dataset: %w", err)
+		}
+	}
+	ds := newDataset(users, time.Now())
+	ds.version = hex.EncodeToString(hash.Sum(nil))
+	ds.stamp = stampOf(info)
+	return ds, nil
+}
+
+// Проверка разобранных записей перед тем, как подменять ими рабочий снимок
+func validateUsersXML(users []UserXML) error {
+	if len(users) == 0 {
+		return errors.New("no rows")
+	}
+	seen := make(map[int]struct{}, len(users))
+	for _, u := range users {
+		if _, ok := seen[u.ID]; ok {
+			return fmt.Errorf("duplicate id %d", u.ID)
+		}
+		seen[u.ID] = struct{}{}
+	}
+	return nil
+}
+
+// Пользователи из XML-файла. Файл читается при первом обращении или явном
+// вызове Load, дальше запросы обслуживаются из неизменяемого снимка,
+// который атомарно подменяется при перезагрузке
+type XMLFileStore struct {
+	path    string
+	current atomic.Pointer[dataset]
+	loadMu  sync.Mutex // не даёт загружать файл параллельно
+}
+
+func NewXMLFileStore(path string) *XMLFileStore {
+	return &XMLFileStore{path: path}
+}
+
+// Load перечитывает файл и подменяет текущий снимок
+func (s *XMLFileStore) Load() error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	return s.load()
+}
+
+func (s *XMLFileStore) load() error {
+	ds, err := loadDataset(s.path)
+	if err != nil {
+		return err
+	}
+	s.current.Store(ds)
+	return nil
+}
+
+// Текущий снимок данных; файл читается только при первом обращении
+func (s *XMLFileStore) snapshot() (*dataset, error) {
+	if ds := s.current.Load(); ds != nil {
+		return ds, nil
+	}
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+	if ds := s.current.Load(); ds == nil {
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+	}
+	return s.current.Load(), nil
+}
+
+func (s *XMLFileStore) List(ctx context.Context) ([]UserServer, error) {
+	ds, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return ds.list(), nil
+}
+
+func (s *XMLFileStore) Get(ctx context.Context, id int) (UserServer, error) {
+	ds, err := s.snapshot()
+	if err != nil {
+		return UserServer{}, err
+	}
+	return ds.get(id)
+}
+
+func (s *XMLFileStore) Search(ctx context.Context, q SearchQuery) (*SearchResult, error) {
+	ds, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return ds.search(ctx, q)
+}
+
+// Состояние файла, по которому определяется, что он изменился
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stampOf(info os.FileInfo) fileStamp {
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+func (s fileStamp) equal(other fileStamp) bool {
+	return s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+// Watch перезагружает файл при изменении его времени модификации или размера.
+// Новый снимок подменяет текущий только при успешной загрузке, иначе остаётся
+// последний корректный, а ошибка передаётся в onError. Работает до отмены ctx
+func (s *XMLFileStore) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Версия файла, которую уже не удалось загрузить, повторно не разбирается
+	var failed fileStamp
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stamp, err := s.reloadIfChanged(failed)
+		if err != nil {
+			failed = stamp
+			if onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Перезагрузка снимка, если файл отличается от загруженного и от skip
+func (s *XMLFileStore) reloadIfChanged(skip fileStamp) (fileStamp, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fileStamp{}, fmt.Errorf("failed to stat dataset file: %w", err)
+	}
+	stamp := stampOf(info)
+	if stamp.equal(skip) {
+		return stamp, nil
+	}
+	if cur := s.current.Load(); cur != nil && stamp.equal(cur.stamp) {
+		return stamp, nil
+	}
+	return stamp, s.Load()
+}
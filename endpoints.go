@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Способы распределения запросов между адресами WithEndpoints
+const (
+	BalanceRoundRobin       = "round_robin"       // по очереди
+	BalanceLeastOutstanding = "least_outstanding" // туда, где меньше незавершённых запросов
+)
+
+// Настройки нескольких адресов SearchServer
+type EndpointPolicy struct {
+	// Balance - BalanceRoundRobin по умолчанию или BalanceLeastOutstanding
+	Balance string
+	// MaxFailures - сколько сбоев подряд выводят адрес из работы; по умолчанию 3.
+	// Сбои - сетевые ошибки, таймауты и ответы 5xx
+	MaxFailures int
+	// EjectFor - на сколько адрес выводится из работы; потом на него снова
+	// идут запросы, и первый же сбой выводит его опять. По умолчанию 10 с
+	EjectFor time.Duration
+}
+
+// WithEndpoints рассылает запросы по нескольким репликам SearchServer.
+// При сетевой ошибке запрос сразу уходит на следующий адрес. URL клиента
+// в запросах тогда не используется и остаётся только ключом кэша и
+// объединения запросов
+func WithEndpoints(urls []string, policy EndpointPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.endpoints = append([]string(nil), urls...)
+		o.endpointPolicy = policy
+	}
+}
+
+type endpoint struct {
+	url          string
+	outstanding  int // незавершённые запросы
+	failures     int // сбои подряд
+	ejectedUntil time.Time
+}
+
+// Адреса и их состояние; безопасно для одновременного использования
+type endpointSet struct {
+	policy EndpointPolicy
+	clock  Clock
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int // начало обхода для round-robin и при равной нагрузке
+}
+
+func newEndpointSet(urls []string, policy EndpointPolicy, clock Clock) *endpointSet {
+	if policy.Balance != BalanceLeastOutstanding {
+		policy.Balance = BalanceRoundRobin
+	}
+	if policy.MaxFailures <= 0 {
+		policy.MaxFailures = 3
+	}
+	if policy.EjectFor <= 0 {
+		policy.EjectFor = 10 * time.Second
+	}
+	set := &endpointSet{policy: policy, clock: clock}
+	for _, u := range urls {
+		set.endpoints = append(set.endpoints, &endpoint{url: u})
+	}
+	return set
+}
+
+// Адрес для следующей попытки среди ещё не опробованных. Если все они
+// выведены из работы, берётся тот, что вернётся раньше всех: лучше
+// попробовать, чем отказать. nil - опробованы все
+func (s *endpointSet) pick(tried []*endpoint) *endpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	var best, fallback *endpoint
+	for i := range s.endpoints {
+		e := s.endpoints[(s.next+i)%len(s.endpoints)]
+		switch {
+		case containsEndpoint(tried, e):
+		case now.Before(e.ejectedUntil):
+			if fallback == nil || e.ejectedUntil.Before(fallback.ejectedUntil) {
+				fallback = e
+			}
+		case best == nil,
+			s.policy.Balance == BalanceLeastOutstanding && e.outstanding < best.outstanding:
+			best = e
+		}
+	}
+	if best == nil {
+		best = fallback
+	}
+	if best != nil {
+		best.outstanding++
+		s.next = (s.next + 1) % len(s.endpoints)
+	}
+	return best
+}
+
+func containsEndpoint(endpoints []*endpoint, e *endpoint) bool {
+	for _, other := range endpoints {
+		if other == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Завершение запроса к e; отменённые вызывающим запросы не считаются ни сбоем, ни успехом
+func (s *endpointSet) done(e *endpoint, outcome int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.outstanding--
+	switch outcome {
+	case breakerSuccess:
+		e.failures = 0
+		e.ejectedUntil = time.Time{}
+	case breakerFailure:
+		if e.failures++; e.failures >= s.policy.MaxFailures {
+			e.ejectedUntil = s.clock.Now().Add(s.policy.EjectFor)
+		}
+	}
+}
+
+// Тело ответа, закрытие которого завершает запрос к адресу
+type endpointBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *endpointBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// Адреса, уже опробованные для одного запроса; общие для основного и
+// дополнительного запроса при хеджировании
+type triedEndpoints struct {
+	mu   sync.Mutex
+	list []*endpoint
+}
+
+func (t *triedEndpoints) pick(set *endpointSet) *endpoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e := set.pick(t.list)
+	if e != nil {
+		t.list = append(t.list, e)
+	}
+	return e
+}
+
+// Отправка запроса на URL клиента или на адреса WithEndpoints
+func (srv *SearchClient) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if srv.hedge != nil {
+		return srv.sendHedged(ctx, req)
+	}
+	return srv.sendFailover(ctx, req, &triedEndpoints{}, nil)
+}
+
+// Отправка с переходом к следующему адресу при сетевой ошибке. first -
+// уже выбранный адрес первой попытки или nil; для первой попытки
+// неопробованный адрес всегда есть
+func (srv *SearchClient) sendFailover(ctx context.Context, req *http.Request, tried *triedEndpoints, first *endpoint) (*http.Response, error) {
+	if srv.endpoints == nil {
+		return srv.doer().Do(req)
+	}
+	var lastErr error
+	for e := first; ; e = nil {
+		if e == nil {
+			e = tried.pick(srv.endpoints)
+		}
+		if e == nil {
+			return nil, lastErr
+		}
+
+		target, err := url.Parse(e.url + "?" + req.URL.RawQuery)
+		if err != nil {
+			srv.endpoints.done(e, breakerFailure)
+			lastErr = err
+			continue
+		}
+		attempt := req.Clone(ctx)
+		attempt.URL, attempt.Host = target, ""
+		resp, err := srv.doer().Do(attempt)
+		if err != nil {
+			if ctx.Err() != nil {
+				srv.endpoints.done(e, breakerIgnore)
+				return nil, err
+			}
+			srv.endpoints.done(e, breakerFailure)
+			lastErr = err
+			continue
+		}
+
+		outcome := breakerSuccess
+		if resp.StatusCode >= http.StatusInternalServerError {
+			outcome = breakerFailure
+		}
+		resp.Body = &endpointBody{ReadCloser: resp.Body, done: func() { srv.endpoints.done(e, outcome) }}
+		return resp, nil
+	}
+}
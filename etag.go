@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Хранилище, которое называет версию снимка без поиска. С ним обработчик
+// отвечает на If-None-Match кодом 304, не выполняя сам поиск
+type VersionedStore interface {
+	Version(ctx context.Context) (string, error)
+}
+
+func (s *XMLFileStore) Version(ctx context.Context) (string, error) {
+	ds, err := s.snapshot()
+	if err != nil {
+		return "", err
+	}
+	return ds.version, nil
+}
+
+func (s *MemoryStore) Version(ctx context.Context) (string, error) {
+	return s.ds.version, nil
+}
+
+// ETag ответа: одна и та же версия снимка и одни и те же параметры дают
+// один и тот же ответ байт в байт
+func responseETag(version string, query url.Values, params searchParams) string {
+	hash := fnv.New64a()
+	io.WriteString(hash, version) //nolint:errcheck
+	for _, part := range []string{
+		query.Encode(),
+		params.query.Match,
+		params.query.QueryMode,
+		params.query.Locale,
+		strconv.FormatBool(params.envelope),
+		strconv.FormatBool(params.lookahead),
+	} {
+		hash.Write([]byte{0})      //nolint:errcheck
+		io.WriteString(hash, part) //nolint:errcheck
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)) + `"`
+}
+
+// Совпадает ли etag с одним из значений If-None-Match; слабые метки
+// сравниваются как сильные, потому что ответ не меняется частично
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Ответ 304 с меткой и версией снимка, если клиент прислал ту же метку
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag, version string) bool {
+	match := r.Header.Get("If-None-Match")
+	if match == "" || !etagMatches(match, etag) {
+		return false
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set(HeaderDatasetVersion, version)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Формат поля registered в dataset.xml
+const registeredLayout = "2006-01-02T15:04:05 -07:00"
+
+// Поле пользователя, доступное клиентам API
+type userField struct {
+	name  string
+	value func(u *UserServer) interface{}
+}
+
+// Все поля UserServer в порядке их вывода
+var userFields = []userField{
+	{"ID", func(u *UserServer) interface{} { return u.ID }},
+	{"Name", func(u *UserServer) interface{} { return u.Name }},
+	{"Age", func(u *UserServer) interface{} { return u.Age }},
+	{"About", func(u *UserServer) interface{} { return u.About }},
+	{"Gender", func(u *UserServer) interface{} { return u.Gender }},
+	{"GUID", func(u *UserServer) interface{} { return u.GUID }},
+	{"IsActive", func(u *UserServer) interface{} { return u.IsActive }},
+	{"Balance", func(u *UserServer) interface{} { return u.Balance }},
+	{"Picture", func(u *UserServer) interface{} { return u.Picture }},
+	{"EyeColor", func(u *UserServer) interface{} { return u.EyeColor }},
+	{"Company", func(u *UserServer) interface{} { return u.Company }},
+	{"Email", func(u *UserServer) interface{} { return u.Email }},
+	{"Phone", func(u *UserServer) interface{} { return u.Phone }},
+	{"Address", func(u *UserServer) interface{} { return u.Address }},
+	{"Registered", func(u *UserServer) interface{} { return u.Registered }},
+	{"FavoriteFruit", func(u *UserServer) interface{} { return u.FavoriteFruit }},
+	{"Score", func(u *UserServer) interface{} { return u.Score }},
+}
+
+// Поиск поля по имени без учёта регистра
+var userFieldsByName = func() map[string]*userField {
+	byName := make(map[string]*userField, len(userFields))
+	for i := range userFields {
+		byName[strings.ToLower(userFields[i].name)] = &userFields[i]
+	}
+	return byName
+}()
+
+// Разбор параметра fields - списка полей через запятую
+func parseFields(value string) ([]*userField, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ",")
+	fields := make([]*userField, 0, len(parts))
+	for _, part := range parts {
+		field, ok := userFieldsByName[strings.ToLower(strings.TrimSpace(part))]
+		if !ok {
+			return nil, badParam("fields", "invalid fields: unknown field %q", part)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Проекция пользователей на запрошенные поля
+func projectUsers(users []UserServer, fields []*userField) []map[string]interface{} {
+	projected := make([]map[string]interface{}, len(users))
+	for i := range users {
+		row := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			row[field.name] = field.value(&users[i])
+		}
+		projected[i] = row
+	}
+	return projected
+}
+
+// Преобразование записи из XML в типизированного пользователя
+func userFromXML(u UserXML) (UserServer, error) {
+	balance, err := parseBalance(u.Balance)
+	if err != nil {
+		return UserServer{}, fmt.Errorf("user %d: %w", u.ID, err)
+	}
+	registered, err := parseRegistered(u.Registered)
+	if err != nil {
+		return UserServer{}, fmt.Errorf("user %d: %w", u.ID, err)
+	}
+	return UserServer{
+		ID:            u.ID,
+		Name:          u.FirstName + " " + u.LastName,
+		Age:           u.Age,
+		About:         u.About,
+		Gender:        u.Gender,
+		GUID:          u.GUID,
+		IsActive:      u.IsActive,
+		Balance:       balance,
+		Picture:       u.Picture,
+		EyeColor:      u.EyeColor,
+		Company:       u.Company,
+		Email:         u.Email,
+		Phone:         u.Phone,
+		Address:       u.Address,
+		Registered:    registered,
+		FavoriteFruit: u.FavoriteFruit,
+	}, nil
+}
+
+// Разбор даты регистрации; пустое значение - нулевое время
+func parseRegistered(value string) (time.Time, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	registered, err := time.Parse(registeredLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid registered %q", value)
+	}
+	return registered, nil
+}
+
+// Разбор суммы вида "$2,144.93" в центы; знак минус допускается и перед
+// "$", и после него. Пустое значение - ноль
+func parseBalance(value string) (int64, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return 0, nil
+	}
+	negative := false
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		negative, s = true, rest
+	}
+	s = strings.TrimPrefix(s, "$")
+	if rest, ok := strings.CutPrefix(s, "-"); ok && !negative {
+		negative, s = true, rest
+	}
+	s = strings.ReplaceAll(s, ",", "")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(frac) || len(frac) > 2 {
+		return 0, fmt.Errorf("invalid balance %q", value)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	dollars, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid balance %q", value)
+	}
+	cents, _ := strconv.ParseInt(frac, 10, 64) //nolint:errcheck
+
+	total := dollars*100 + cents
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
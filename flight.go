@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// WithDeduplication объединяет одновременные одинаковые FindUsers - тот же
+// URL, токен и параметры запроса - в один HTTP-запрос с общим результатом.
+// Общий запрос не зависит от отмены и срока ctx отдельных вызовов и
+// отменяется, только когда его перестают ждать все вызвавшие
+func WithDeduplication() ClientOption {
+	return func(o *clientOptions) { o.dedupe = true }
+}
+
+// Выполняемые сейчас запросы по ключу
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done    chan struct{}
+	resp    *SearchResponse
+	err     error
+	waiters int // сколько вызовов ещё ждут результат
+	cancel  context.CancelFunc
+}
+
+// Выполнение call или ожидание уже начатого запроса с тем же ключом.
+// Ошибка ctx возвращается сразу, общий запрос при этом продолжается,
+// пока его ждёт кто-то ещё
+func (g *flightGroup) do(ctx context.Context, key string, call func(ctx context.Context) (*SearchResponse, error)) (*SearchResponse, error) {
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if !ok {
+		// Значения ctx, например трассировка, сохраняются, отмена - нет
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &flightCall{done: make(chan struct{}), cancel: cancel}
+		if g.calls == nil {
+			g.calls = make(map[string]*flightCall)
+		}
+		g.calls[key] = c
+		go func() {
+			c.resp, c.err = call(callCtx)
+			g.forget(key, c)
+			cancel()
+			close(c.done)
+		}()
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.resp, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		if c.waiters--; c.waiters == 0 {
+			// Отменённый запрос больше не выдаётся новым вызовам
+			c.cancel()
+			g.forgetLocked(key, c)
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (g *flightGroup) forget(key string, c *flightCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.forgetLocked(key, c)
+}
+
+func (g *flightGroup) forgetLocked(key string, c *flightCall) {
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
+
+// Запрос через общий вызов, если объединение включено. Каждый вызвавший
+// получает свою копию ответа
+func (srv *SearchClient) withDeduplication(ctx context.Context, params url.Values, call func(ctx context.Context) (*SearchResponse, error)) (*SearchResponse, error) {
+	if srv.flights == nil {
+		return call(ctx)
+	}
+	key := srv.URL + "\x00" + srv.AccessToken + "\x00" + params.Encode()
+	resp, err := srv.flights.do(ctx, key, call)
+	switch {
+	case err != nil && err == ctx.Err():
+		return nil, requestError(ctx, params, err)
+	case err != nil:
+		return nil, err
+	}
+	return copyResponse(resp), nil
+}
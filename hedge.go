@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Настройки хеджирования: если первый запрос не получил ответа за Delay,
+// отправляется второй - на другой адрес, если их несколько, - и берётся
+// тот ответ, что придёт раньше. Опоздавший запрос отменяется
+type HedgePolicy struct {
+	// Delay - сколько ждать ответа на первый запрос; по умолчанию 100 мс.
+	// Обычно ставится около p95 времени ответа
+	Delay time.Duration
+}
+
+// WithHedging включает хеджирование запросов
+func WithHedging(policy HedgePolicy) ClientOption {
+	return func(o *clientOptions) { o.hedge = &policy }
+}
+
+// Счётчики хеджирования с момента создания клиента
+type HedgeStats struct {
+	Requests int64 // запросы, отправленные с хеджированием
+	Fired    int64 // сколько раз был отправлен второй запрос
+	Won      int64 // сколько раз второй запрос ответил раньше первого
+}
+
+type hedger struct {
+	policy HedgePolicy
+
+	requests atomic.Int64
+	fired    atomic.Int64
+	won      atomic.Int64
+}
+
+func newHedger(policy HedgePolicy) *hedger {
+	if policy.Delay <= 0 {
+		policy.Delay = 100 * time.Millisecond
+	}
+	return &hedger{policy: policy}
+}
+
+// HedgeStats возвращает счётчики хеджирования; без него - нули
+func (srv *SearchClient) HedgeStats() HedgeStats {
+	if srv.hedge == nil {
+		return HedgeStats{}
+	}
+	return HedgeStats{
+		Requests: srv.hedge.requests.Load(),
+		Fired:    srv.hedge.fired.Load(),
+		Won:      srv.hedge.won.Load(),
+	}
+}
+
+type hedgeResult struct {
+	index int // 0 - первый запрос, 1 - второй
+	resp  *http.Response
+	err   error
+}
+
+// Тело ответа победителя, закрытие которого отменяет его контекст
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Отправка с хеджированием. Любой HTTP-ответ, в том числе 5xx, считается
+// ответом; ошибка одного запроса ждёт результата другого
+func (srv *SearchClient) sendHedged(ctx context.Context, req *http.Request) (*http.Response, error) {
+	srv.hedge.requests.Add(1)
+	tried := &triedEndpoints{}
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	launch := func(first *endpoint) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := srv.sendFailover(attemptCtx, req.Clone(attemptCtx), tried, first)
+			results <- hedgeResult{index, resp, err}
+		}()
+	}
+
+	launch(nil)
+	timer := srv.clockOrDefault().After(srv.hedge.policy.Delay)
+	pending := 1
+	errs := make([]error, 2)
+	for {
+		select {
+		case <-timer:
+			timer = nil
+			// Адрес выбирается заранее: если неопробованных не осталось,
+			// дополнительный запрос не отправляется и не считается
+			var first *endpoint
+			if srv.endpoints != nil {
+				if first = tried.pick(srv.endpoints); first == nil {
+					continue
+				}
+			}
+			srv.hedge.fired.Add(1)
+			launch(first)
+			pending++
+		case r := <-results:
+			pending--
+			if r.err != nil {
+				cancels[r.index]()
+				errs[r.index] = r.err
+				if pending > 0 {
+					continue
+				}
+				// Ошибкой завершились оба запроса или первый до срока Delay;
+				// возвращается ошибка первого
+				for _, cancel := range cancels {
+					cancel()
+				}
+				return nil, errs[0]
+			}
+
+			if r.index == 1 {
+				srv.hedge.won.Add(1)
+			}
+			for i, cancel := range cancels {
+				if i != r.index {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				// Опоздавший ответ закрывается, чтобы вернуть соединение
+				go func() {
+					if late := <-results; late.resp != nil {
+						late.resp.Body.Close()
+					}
+				}()
+			}
+			r.resp.Body = &cancelBody{ReadCloser: r.resp.Body, cancel: cancels[r.index]}
+			return r.resp, nil
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Режимы обработки параметра query
+const (
+	QueryModeIndex     = "index"     // слова через инвертированный индекс
+	QueryModeSubstring = "substring" // подстрока в Name или About, как раньше
+)
+
+// Инвертированный индекс по словам Name и About. Строится один раз при
+// загрузке снимка и дальше только читается
+type invertedIndex struct {
+	postings map[string][]int // слово -> возрастающий список индексов users
+	terms    []string         // все слова по возрастанию, для поиска по префиксу
+	name     [][]string       // слова Name каждого пользователя по порядку
+	about    [][]string       // слова About каждого пользователя по порядку
+	avgName  float64          // средняя длина Name и About в словах, для ранжирования
+	avgAbout float64
+
+	// преобразование текста режима сравнения, применяется и к запросу
+	transform func(string) string
+}
+
+// Разбиение текста на слова: последовательности букв и цифр
+// вместе с комбинируемыми знаками
+func tokenize(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// Индекс по словам, предварительно преобразованным transform
+func newInvertedIndex(users []UserServer, transform func(string) string) *invertedIndex {
+	idx := &invertedIndex{
+		postings:  make(map[string][]int),
+		name:      make([][]string, len(users)),
+		about:     make([][]string, len(users)),
+		transform: transform,
+	}
+	for i := range users {
+		idx.name[i] = tokenize(transform(users[i].Name))
+		idx.about[i] = tokenize(transform(users[i].About))
+		for _, tokens := range [][]string{idx.name[i], idx.about[i]} {
+			for _, token := range tokens {
+				// Пользователи обходятся по возрастанию, поэтому достаточно
+				// сравнить с последним добавленным
+				list := idx.postings[token]
+				if len(list) == 0 || list[len(list)-1] != i {
+					idx.postings[token] = append(list, i)
+				}
+			}
+		}
+	}
+	for i := range users {
+		idx.avgName += float64(len(idx.name[i]))
+		idx.avgAbout += float64(len(idx.about[i]))
+	}
+	if len(users) > 0 {
+		idx.avgName /= float64(len(users))
+		idx.avgAbout /= float64(len(users))
+	}
+
+	idx.terms = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+	return idx
+}
+
+// Условие текстового запроса: слово, префикс или фраза
+type textClause struct {
+	tokens []string // для фразы - несколько слов подряд
+	prefix bool     // последнее слово ищется как префикс
+}
+
+// Индексы пользователей, в Name или About которых есть слово, префикс или
+// фраза, по возрастанию
+func (idx *invertedIndex) searchClause(clause textClause) []int {
+	last := len(clause.tokens) - 1
+	var candidates []int
+	for n, token := range clause.tokens {
+		var list []int
+		if n == last && clause.prefix {
+			list = idx.prefixPostings(token)
+		} else {
+			list = idx.postings[token]
+		}
+		if n == 0 {
+			candidates = list
+		} else {
+			candidates = intersectSorted(candidates, list)
+		}
+	}
+	if last == 0 {
+		return candidates
+	}
+
+	// Для фразы проверяем, что слова идут подряд в одном из полей
+	phrase := candidates[:0:0]
+	for _, i := range candidates {
+		if containsPhrase(idx.name[i], clause) || containsPhrase(idx.about[i], clause) {
+			phrase = append(phrase, i)
+		}
+	}
+	return phrase
+}
+
+// Объединение списков всех слов, начинающихся с prefix
+func (idx *invertedIndex) prefixPostings(prefix string) []int {
+	start := sort.SearchStrings(idx.terms, prefix)
+	var lists [][]int
+	for _, term := range idx.terms[start:] {
+		if !strings.HasPrefix(term, prefix) {
+			break
+		}
+		lists = append(lists, idx.postings[term])
+	}
+	if len(lists) == 1 {
+		return lists[0]
+	}
+	return unionSorted(lists)
+}
+
+func containsPhrase(tokens []string, clause textClause) bool {
+	last := len(clause.tokens) - 1
+	for start := 0; start+last < len(tokens); start++ {
+		matched := true
+		for n, token := range clause.tokens {
+			got := tokens[start+n]
+			if n == last && clause.prefix {
+				matched = strings.HasPrefix(got, token)
+			} else {
+				matched = got == token
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Пересечение двух возрастающих списков
+func intersectSorted(a, b []int) []int {
+	result := make([]int, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// Объединение возрастающих списков без повторов
+func unionSorted(lists [][]int) []int {
+	var result []int
+	for _, list := range lists {
+		result = append(result, list...)
+	}
+	sort.Ints(result)
+	unique := result[:0]
+	for i, v := range result {
+		if i == 0 || v != result[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"iter"
+)
+
+// Настройки обхода всех страниц
+type allOptions struct {
+	prefetch int
+}
+
+type AllOption func(*allOptions)
+
+// WithPrefetch загружает до pages следующих страниц, пока обрабатывается текущая
+func WithPrefetch(pages int) AllOption {
+	return func(o *allOptions) {
+		o.prefetch = pages
+	}
+}
+
+// All возвращает всех найденных пользователей, сам переходя по страницам:
+// по курсору, а если сервер его не выдал - по смещению. Обход прекращается
+// на первой ошибке, которая передаётся вместе с пустым User, и при отмене ctx
+func (srv *SearchClient) All(ctx context.Context, req SearchRequest, opts ...AllOption) iter.Seq2[User, error] {
+	var o allOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(yield func(User, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		next := srv.pageWalker(ctx, req)
+		if o.prefetch > 0 {
+			next = prefetchPages(ctx, next, o.prefetch)
+		}
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(User{}, err)
+				return
+			}
+			resp, err := next()
+			if err != nil {
+				yield(User{}, err)
+				return
+			}
+			if resp == nil {
+				return
+			}
+			for _, u := range resp.Users {
+				if !yield(u, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Each вызывает fn для каждого найденного пользователя; вариант All для кода
+// без range по функциям. Ошибка fn прекращает обход и возвращается как есть
+func (srv *SearchClient) Each(ctx context.Context, req SearchRequest, fn func(User) error, opts ...AllOption) error {
+	for u, err := range srv.All(ctx, req, opts...) {
+		if err != nil {
+			return err
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Последовательная загрузка страниц; после последней возвращает nil
+func (srv *SearchClient) pageWalker(ctx context.Context, req SearchRequest) func() (*SearchResponse, error) {
+	if req.Limit == 0 {
+		req.Limit = srv.pageLimit()
+	}
+	var prev *SearchResponse
+	return func() (*SearchResponse, error) {
+		var resp *SearchResponse
+		var err error
+		switch {
+		case prev == nil:
+			resp, err = srv.FindUsersContext(ctx, req)
+		case !prev.NextPage || len(prev.Users) == 0:
+			return nil, nil
+		case prev.NextCursor != "":
+			resp, err = srv.FindNextUsersContext(ctx, req, prev)
+		default:
+			req.Offset += len(prev.Users)
+			resp, err = srv.FindUsersContext(ctx, req)
+		}
+		prev = resp
+		return resp, err
+	}
+}
+
+type pageResult struct {
+	resp *SearchResponse
+	err  error
+}
+
+// Загрузка страниц в отдельной горутине не более чем на pages вперёд.
+// Горутина завершается после последней страницы, ошибки или отмены ctx
+func prefetchPages(ctx context.Context, next func() (*SearchResponse, error), pages int) func() (*SearchResponse, error) {
+	// Ещё одна страница может ждать отправки в самой горутине
+	results := make(chan pageResult, pages-1)
+	go func() {
+		defer close(results)
+		for {
+			resp, err := next()
+			select {
+			case results <- pageResult{resp, err}:
+			case <-ctx.Done():
+				return
+			}
+			if resp == nil || err != nil {
+				return
+			}
+		}
+	}()
+	// Канал закрывается раньше последней страницы только при отмене ctx, а
+	// страница, загруженная к моменту отмены, уже не нужна
+	return func() (*SearchResponse, error) {
+		r := <-results
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return r.resp, r.err
+	}
+}
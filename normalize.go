@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Режимы сравнения слов запроса с Name и About
+const (
+	MatchExact           = "exact"            // побайтовое совпадение
+	MatchCaseInsensitive = "case-insensitive" // без учёта регистра
+	MatchNormalized      = "normalized"       // без учёта регистра и диакритики
+)
+
+var matchModes = []string{MatchExact, MatchCaseInsensitive, MatchNormalized}
+
+// Преобразование текста перед разбиением на слова для каждого режима
+var matchTransforms = map[string]func(string) string{
+	MatchExact:           func(s string) string { return s },
+	MatchCaseInsensitive: foldCase,
+	MatchNormalized:      normalizeText,
+}
+
+func isMatchMode(mode string) bool {
+	_, ok := matchTransforms[mode]
+	return ok
+}
+
+// Полная свёртка регистра Unicode, как cases.Fold из golang.org/x/text.
+// Для большинства символов она совпадает с переводом в верхний регистр и
+// обратно, остальные берутся из сгенерированной таблицы foldSpecial
+func foldCase(s string) string {
+	if isASCII(s) {
+		return strings.ToLower(s)
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if folded, ok := foldSpecial[r]; ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(unicode.ToLower(unicode.ToUpper(r)))
+		}
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// Свёртка регистра, разложение NFKD и отбрасывание комбинируемых знаков.
+// Таблица разложения decompositions сгенерирована из golang.org/x/text и
+// покрывает латиницу, греческий, кириллицу, общие символы, лигатуры и
+// полноширинные формы; остальные символы, кроме комбинируемых знаков,
+// остаются как есть. Буквы без разложения, например ł и ø, не меняются
+func normalizeText(s string) string {
+	s = foldCase(s)
+	if isASCII(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		writeNormalizedRune(&b, r)
+	}
+	return b.String()
+}
+
+// Разложение символа уже свёрнутой строки без комбинируемых знаков
+func writeNormalizedRune(b *strings.Builder, r rune) {
+	switch base, ok := decompositions[r]; {
+	case ok:
+		b.WriteString(base)
+	case !unicode.Is(unicode.Mn, r):
+		b.WriteRune(r)
+	}
+}
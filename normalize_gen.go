@@ -0,0 +1,66 @@
+//go:build ignore
+
+// Генератор normalize_tables.go. Нужен golang.org/x/text, поэтому
+// запускается в модуле, где он есть:
+//
+//	go run normalize_gen.go | gofmt > normalize_tables.go
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Диапазоны, для которых строится таблица разложения: латиница, греческий,
+// кириллица и общие символы до U+24FF, лигатуры и полноширинные формы
+func decomposable(r rune) bool {
+	return r < 0x2500 || r >= 0xfb00 && r <= 0xfb06 || r >= 0xff00 && r <= 0xffef
+}
+
+func main() {
+	folder := cases.Fold()
+	stripMarks := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.Is(unicode.Mn, r) {
+				return -1
+			}
+			return r
+		}, s)
+	}
+
+	out := os.Stdout
+	fmt.Fprintln(out, "// Code generated by normalize_gen.go; DO NOT EDIT.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "package main")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "// Полная свёртка регистра Unicode там, где она отличается от")
+	fmt.Fprintln(out, "// unicode.ToLower(unicode.ToUpper(r))")
+	fmt.Fprintln(out, "var foldSpecial = map[rune]string{")
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if !unicode.IsPrint(r) {
+			continue
+		}
+		if folded := folder.String(string(r)); folded != string(unicode.ToLower(unicode.ToUpper(r))) {
+			fmt.Fprintf(out, "\t0x%04x: %q, // %c\n", r, folded, r)
+		}
+	}
+	fmt.Fprintln(out, "}")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "// NFKD без комбинируемых знаков и со свёрткой регистра для символов,")
+	fmt.Fprintln(out, "// которые свёртка оставляет на месте")
+	fmt.Fprintln(out, "var decompositions = map[rune]string{")
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if !decomposable(r) || !unicode.IsPrint(r) || unicode.Is(unicode.Mn, r) || folder.String(string(r)) != string(r) {
+			continue
+		}
+		if base := folder.String(stripMarks(norm.NFKD.String(string(r)))); base != string(r) {
+			fmt.Fprintf(out, "\t0x%04x: %q, // %c\n", r, base, r)
+		}
+	}
+	fmt.Fprintln(out, "}")
+}
@@ -0,0 +1,1299 @@
+// Code generated by normalize_gen.go; DO NOT EDIT.
+
+package main
+
+// Полная свёртка регистра Unicode там, где она отличается от
+// unicode.ToLower(unicode.ToUpper(r))
+var foldSpecial = map[rune]string{
+	0x00df: "ss",  // ß
+	0x0130: "i̇",  // İ
+	0x0131: "ı",   // ı
+	0x0149: "ʼn",  // ŉ
+	0x01f0: "ǰ",  // ǰ
+	0x0390: "ΐ", // ΐ
+	0x03b0: "ΰ", // ΰ
+	0x0587: "եւ",  // և
+	0x13f8: "Ᏸ",   // ᏸ
+	0x13f9: "Ᏹ",   // ᏹ
+	0x13fa: "Ᏺ",   // ᏺ
+	0x13fb: "Ᏻ",   // ᏻ
+	0x13fc: "Ᏼ",   // ᏼ
+	0x13fd: "Ᏽ",   // ᏽ
+	0x1e96: "ẖ",  // ẖ
+	0x1e97: "ẗ",  // ẗ
+	0x1e98: "ẘ",  // ẘ
+	0x1e99: "ẙ",  // ẙ
+	0x1e9a: "aʾ",  // ẚ
+	0x1e9e: "ss",  // ẞ
+	0x1f50: "ὐ",  // ὐ
+	0x1f52: "ὒ", // ὒ
+	0x1f54: "ὔ", // ὔ
+	0x1f56: "ὖ", // ὖ
+	0x1f80: "ἀι",  // ᾀ
+	0x1f81: "ἁι",  // ᾁ
+	0x1f82: "ἂι",  // ᾂ
+	0x1f83: "ἃι",  // ᾃ
+	0x1f84: "ἄι",  // ᾄ
+	0x1f85: "ἅι",  // ᾅ
+	0x1f86: "ἆι",  // ᾆ
+	0x1f87: "ἇι",  // ᾇ
+	0x1f88: "ἀι",  // ᾈ
+	0x1f89: "ἁι",  // ᾉ
+	0x1f8a: "ἂι",  // ᾊ
+	0x1f8b: "ἃι",  // ᾋ
+	0x1f8c: "ἄι",  // ᾌ
+	0x1f8d: "ἅι",  // ᾍ
+	0x1f8e: "ἆι",  // ᾎ
+	0x1f8f: "ἇι",  // ᾏ
+	0x1f90: "ἠι",  // ᾐ
+	0x1f91: "ἡι",  // ᾑ
+	0x1f92: "ἢι",  // ᾒ
+	0x1f93: "ἣι",  // ᾓ
+	0x1f94: "ἤι",  // ᾔ
+	0x1f95: "ἥι",  // ᾕ
+	0x1f96: "ἦι",  // ᾖ
+	0x1f97: "ἧι",  // ᾗ
+	0x1f98: "ἠι",  // ᾘ
+	0x1f99: "ἡι",  // ᾙ
+	0x1f9a: "ἢι",  // ᾚ
+	0x1f9b: "ἣι",  // ᾛ
+	0x1f9c: "ἤι",  // ᾜ
+	0x1f9d: "ἥι",  // ᾝ
+	0x1f9e: "ἦι",  // ᾞ
+	0x1f9f: "ἧι",  // ᾟ
+	0x1fa0: "ὠι",  // ᾠ
+	0x1fa1: "ὡι",  // ᾡ
+	0x1fa2: "ὢι",  // ᾢ
+	0x1fa3: "ὣι",  // ᾣ
+	0x1fa4: "ὤι",  // ᾤ
+	0x1fa5: "ὥι",  // ᾥ
+	0x1fa6: "ὦι",  // ᾦ
+	0x1fa7: "ὧι",  // ᾧ
+	0x1fa8: "ὠι",  // ᾨ
+	0x1fa9: "ὡι",  // ᾩ
+	0x1faa: "ὢι",  // ᾪ
+	0x1fab: "ὣι",  // ᾫ
+	0x1fac: "ὤι",  // ᾬ
+	0x1fad: "ὥι",  // ᾭ
+	0x1fae: "ὦι",  // ᾮ
+	0x1faf: "ὧι",  // ᾯ
+	0x1fb2: "ὰι",  // ᾲ
+	0x1fb3: "αι",  // ᾳ
+	0x1fb4: "άι",  // ᾴ
+	0x1fb6: "ᾶ",  // ᾶ
+	0x1fb7: "ᾶι", // ᾷ
+	0x1fbc: "αι",  // ᾼ
+	0x1fc2: "ὴι",  // ῂ
+	0x1fc3: "ηι",  // ῃ
+	0x1fc4: "ήι",  // ῄ
+	0x1fc6: "ῆ",  // ῆ
+	0x1fc7: "ῆι", // ῇ
+	0x1fcc: "ηι",  // ῌ
+	0x1fd2: "ῒ", // ῒ
+	0x1fd3: "ΐ", // ΐ
+	0x1fd6: "ῖ",  // ῖ
+	0x1fd7: "ῗ", // ῗ
+	0x1fe2: "ῢ", // ῢ
+	0x1fe3: "ΰ", // ΰ
+	0x1fe4: "ῤ",  // ῤ
+	0x1fe6: "ῦ",  // ῦ
+	0x1fe7: "ῧ", // ῧ
+	0x1ff2: "ὼι",  // ῲ
+	0x1ff3: "ωι",  // ῳ
+	0x1ff4: "ώι",  // ῴ
+	0x1ff6: "ῶ",  // ῶ
+	0x1ff7: "ῶι", // ῷ
+	0x1ffc: "ωι",  // ῼ
+	0xab70: "Ꭰ",   // ꭰ
+	0xab71: "Ꭱ",   // ꭱ
+	0xab72: "Ꭲ",   // ꭲ
+	0xab73: "Ꭳ",   // ꭳ
+	0xab74: "Ꭴ",   // ꭴ
+	0xab75: "Ꭵ",   // ꭵ
+	0xab76: "Ꭶ",   // ꭶ
+	0xab77: "Ꭷ",   // ꭷ
+	0xab78: "Ꭸ",   // ꭸ
+	0xab79: "Ꭹ",   // ꭹ
+	0xab7a: "Ꭺ",   // ꭺ
+	0xab7b: "Ꭻ",   // ꭻ
+	0xab7c: "Ꭼ",   // ꭼ
+	0xab7d: "Ꭽ",   // ꭽ
+	0xab7e: "Ꭾ",   // ꭾ
+	0xab7f: "Ꭿ",   // ꭿ
+	0xab80: "Ꮀ",   // ꮀ
+	0xab81: "Ꮁ",   // ꮁ
+	0xab82: "Ꮂ",   // ꮂ
+	0xab83: "Ꮃ",   // ꮃ
+	0xab84: "Ꮄ",   // ꮄ
+	0xab85: "Ꮅ",   // ꮅ
+	0xab86: "Ꮆ",   // ꮆ
+	0xab87: "Ꮇ",   // ꮇ
+	0xab88: "Ꮈ",   // ꮈ
+	0xab89: "Ꮉ",   // ꮉ
+	0xab8a: "Ꮊ",   // ꮊ
+	0xab8b: "Ꮋ",   // ꮋ
+	0xab8c: "Ꮌ",   // ꮌ
+	0xab8d: "Ꮍ",   // ꮍ
+	0xab8e: "Ꮎ",   // ꮎ
+	0xab8f: "Ꮏ",   // ꮏ
+	0xab90: "Ꮐ",   // ꮐ
+	0xab91: "Ꮑ",   // ꮑ
+	0xab92: "Ꮒ",   // ꮒ
+	0xab93: "Ꮓ",   // ꮓ
+	0xab94: "Ꮔ",   // ꮔ
+	0xab95: "Ꮕ",   // ꮕ
+	0xab96: "Ꮖ",   // ꮖ
+	0xab97: "Ꮗ",   // ꮗ
+	0xab98: "Ꮘ",   // ꮘ
+	0xab99: "Ꮙ",   // ꮙ
+	0xab9a: "Ꮚ",   // ꮚ
+	0xab9b: "Ꮛ",   // ꮛ
+	0xab9c: "Ꮜ",   // ꮜ
+	0xab9d: "Ꮝ",   // ꮝ
+	0xab9e: "Ꮞ",   // ꮞ
+	0xab9f: "Ꮟ",   // ꮟ
+	0xaba0: "Ꮠ",   // ꮠ
+	0xaba1: "Ꮡ",   // ꮡ
+	0xaba2: "Ꮢ",   // ꮢ
+	0xaba3: "Ꮣ",   // ꮣ
+	0xaba4: "Ꮤ",   // ꮤ
+	0xaba5: "Ꮥ",   // ꮥ
+	0xaba6: "Ꮦ",   // ꮦ
+	0xaba7: "Ꮧ",   // ꮧ
+	0xaba8: "Ꮨ",   // ꮨ
+	0xaba9: "Ꮩ",   // ꮩ
+	0xabaa: "Ꮪ",   // ꮪ
+	0xabab: "Ꮫ",   // ꮫ
+	0xabac: "Ꮬ",   // ꮬ
+	0xabad: "Ꮭ",   // ꮭ
+	0xabae: "Ꮮ",   // ꮮ
+	0xabaf: "Ꮯ",   // ꮯ
+	0xabb0: "Ꮰ",   // ꮰ
+	0xabb1: "Ꮱ",   // ꮱ
+	0xabb2: "Ꮲ",   // ꮲ
+	0xabb3: "Ꮳ",   // ꮳ
+	0xabb4: "Ꮴ",   // ꮴ
+	0xabb5: "Ꮵ",   // ꮵ
+	0xabb6: "Ꮶ",   // ꮶ
+	0xabb7: "Ꮷ",   // ꮷ
+	0xabb8: "Ꮸ",   // ꮸ
+	0xabb9: "Ꮹ",   // ꮹ
+	0xabba: "Ꮺ",   // ꮺ
+	0xabbb: "Ꮻ",   // ꮻ
+	0xabbc: "Ꮼ",   // ꮼ
+	0xabbd: "Ꮽ",   // ꮽ
+	0xabbe: "Ꮾ",   // ꮾ
+	0xabbf: "Ꮿ",   // ꮿ
+	0xfb00: "ff",  // ﬀ
+	0xfb01: "fi",  // ﬁ
+	0xfb02: "fl",  // ﬂ
+	0xfb03: "ffi", // ﬃ
+	0xfb04: "ffl", // ﬄ
+	0xfb05: "st",  // ﬅ
+	0xfb06: "st",  // ﬆ
+	0xfb13: "մն",  // ﬓ
+	0xfb14: "մե",  // ﬔ
+	0xfb15: "մի",  // ﬕ
+	0xfb16: "վն",  // ﬖ
+	0xfb17: "մխ",  // ﬗ
+}
+
+// NFKD без комбинируемых знаков и со свёрткой регистра для символов,
+// которые свёртка оставляет на месте
+var decompositions = map[rune]string{
+	0x00a8: " ",    // ¨
+	0x00aa: "a",    // ª
+	0x00af: " ",    // ¯
+	0x00b2: "2",    // ²
+	0x00b3: "3",    // ³
+	0x00b4: " ",    // ´
+	0x00b8: " ",    // ¸
+	0x00b9: "1",    // ¹
+	0x00ba: "o",    // º
+	0x00bc: "1⁄4",  // ¼
+	0x00bd: "1⁄2",  // ½
+	0x00be: "3⁄4",  // ¾
+	0x00e0: "a",    // à
+	0x00e1: "a",    // á
+	0x00e2: "a",    // â
+	0x00e3: "a",    // ã
+	0x00e4: "a",    // ä
+	0x00e5: "a",    // å
+	0x00e7: "c",    // ç
+	0x00e8: "e",    // è
+	0x00e9: "e",    // é
+	0x00ea: "e",    // ê
+	0x00eb: "e",    // ë
+	0x00ec: "i",    // ì
+	0x00ed: "i",    // í
+	0x00ee: "i",    // î
+	0x00ef: "i",    // ï
+	0x00f1: "n",    // ñ
+	0x00f2: "o",    // ò
+	0x00f3: "o",    // ó
+	0x00f4: "o",    // ô
+	0x00f5: "o",    // õ
+	0x00f6: "o",    // ö
+	0x00f9: "u",    // ù
+	0x00fa: "u",    // ú
+	0x00fb: "u",    // û
+	0x00fc: "u",    // ü
+	0x00fd: "y",    // ý
+	0x00ff: "y",    // ÿ
+	0x0101: "a",    // ā
+	0x0103: "a",    // ă
+	0x0105: "a",    // ą
+	0x0107: "c",    // ć
+	0x0109: "c",    // ĉ
+	0x010b: "c",    // ċ
+	0x010d: "c",    // č
+	0x010f: "d",    // ď
+	0x0113: "e",    // ē
+	0x0115: "e",    // ĕ
+	0x0117: "e",    // ė
+	0x0119: "e",    // ę
+	0x011b: "e",    // ě
+	0x011d: "g",    // ĝ
+	0x011f: "g",    // ğ
+	0x0121: "g",    // ġ
+	0x0123: "g",    // ģ
+	0x0125: "h",    // ĥ
+	0x0129: "i",    // ĩ
+	0x012b: "i",    // ī
+	0x012d: "i",    // ĭ
+	0x012f: "i",    // į
+	0x0133: "ij",   // ĳ
+	0x0135: "j",    // ĵ
+	0x0137: "k",    // ķ
+	0x013a: "l",    // ĺ
+	0x013c: "l",    // ļ
+	0x013e: "l",    // ľ
+	0x0140: "l·",   // ŀ
+	0x0144: "n",    // ń
+	0x0146: "n",    // ņ
+	0x0148: "n",    // ň
+	0x014d: "o",    // ō
+	0x014f: "o",    // ŏ
+	0x0151: "o",    // ő
+	0x0155: "r",    // ŕ
+	0x0157: "r",    // ŗ
+	0x0159: "r",    // ř
+	0x015b: "s",    // ś
+	0x015d: "s",    // ŝ
+	0x015f: "s",    // ş
+	0x0161: "s",    // š
+	0x0163: "t",    // ţ
+	0x0165: "t",    // ť
+	0x0169: "u",    // ũ
+	0x016b: "u",    // ū
+	0x016d: "u",    // ŭ
+	0x016f: "u",    // ů
+	0x0171: "u",    // ű
+	0x0173: "u",    // ų
+	0x0175: "w",    // ŵ
+	0x0177: "y",    // ŷ
+	0x017a: "z",    // ź
+	0x017c: "z",    // ż
+	0x017e: "z",    // ž
+	0x01a1: "o",    // ơ
+	0x01b0: "u",    // ư
+	0x01c6: "dz",   // ǆ
+	0x01c9: "lj",   // ǉ
+	0x01cc: "nj",   // ǌ
+	0x01ce: "a",    // ǎ
+	0x01d0: "i",    // ǐ
+	0x01d2: "o",    // ǒ
+	0x01d4: "u",    // ǔ
+	0x01d6: "u",    // ǖ
+	0x01d8: "u",    // ǘ
+	0x01da: "u",    // ǚ
+	0x01dc: "u",    // ǜ
+	0x01df: "a",    // ǟ
+	0x01e1: "a",    // ǡ
+	0x01e3: "æ",    // ǣ
+	0x01e7: "g",    // ǧ
+	0x01e9: "k",    // ǩ
+	0x01eb: "o",    // ǫ
+	0x01ed: "o",    // ǭ
+	0x01ef: "ʒ",    // ǯ
+	0x01f3: "dz",   // ǳ
+	0x01f5: "g",    // ǵ
+	0x01f9: "n",    // ǹ
+	0x01fb: "a",    // ǻ
+	0x01fd: "æ",    // ǽ
+	0x01ff: "ø",    // ǿ
+	0x0201: "a",    // ȁ
+	0x0203: "a",    // ȃ
+	0x0205: "e",    // ȅ
+	0x0207: "e",    // ȇ
+	0x0209: "i",    // ȉ
+	0x020b: "i",    // ȋ
+	0x020d: "o",    // ȍ
+	0x020f: "o",    // ȏ
+	0x0211: "r",    // ȑ
+	0x0213: "r",    // ȓ
+	0x0215: "u",    // ȕ
+	0x0217: "u",    // ȗ
+	0x0219: "s",    // ș
+	0x021b: "t",    // ț
+	0x021f: "h",    // ȟ
+	0x0227: "a",    // ȧ
+	0x0229: "e",    // ȩ
+	0x022b: "o",    // ȫ
+	0x022d: "o",    // ȭ
+	0x022f: "o",    // ȯ
+	0x0231: "o",    // ȱ
+	0x0233: "y",    // ȳ
+	0x02b0: "h",    // ʰ
+	0x02b1: "ɦ",    // ʱ
+	0x02b2: "j",    // ʲ
+	0x02b3: "r",    // ʳ
+	0x02b4: "ɹ",    // ʴ
+	0x02b5: "ɻ",    // ʵ
+	0x02b6: "ʁ",    // ʶ
+	0x02b7: "w",    // ʷ
+	0x02b8: "y",    // ʸ
+	0x02d8: " ",    // ˘
+	0x02d9: " ",    // ˙
+	0x02da: " ",    // ˚
+	0x02db: " ",    // ˛
+	0x02dc: " ",    // ˜
+	0x02dd: " ",    // ˝
+	0x02e0: "ɣ",    // ˠ
+	0x02e1: "l",    // ˡ
+	0x02e2: "s",    // ˢ
+	0x02e3: "x",    // ˣ
+	0x02e4: "ʕ",    // ˤ
+	0x0374: "ʹ",    // ʹ
+	0x037a: " ",    // ͺ
+	0x037e: ";",    // ;
+	0x0384: " ",    // ΄
+	0x0385: " ",    // ΅
+	0x0387: "·",    // ·
+	0x03ac: "α",    // ά
+	0x03ad: "ε",    // έ
+	0x03ae: "η",    // ή
+	0x03af: "ι",    // ί
+	0x03ca: "ι",    // ϊ
+	0x03cb: "υ",    // ϋ
+	0x03cc: "ο",    // ό
+	0x03cd: "υ",    // ύ
+	0x03ce: "ω",    // ώ
+	0x03d2: "υ",    // ϒ
+	0x03d3: "υ",    // ϓ
+	0x03d4: "υ",    // ϔ
+	0x03f2: "σ",    // ϲ
+	0x0439: "и",    // й
+	0x0450: "е",    // ѐ
+	0x0451: "е",    // ё
+	0x0453: "г",    // ѓ
+	0x0457: "і",    // ї
+	0x045c: "к",    // ќ
+	0x045d: "и",    // ѝ
+	0x045e: "у",    // ў
+	0x0477: "ѵ",    // ѷ
+	0x04c2: "ж",    // ӂ
+	0x04d1: "а",    // ӑ
+	0x04d3: "а",    // ӓ
+	0x04d7: "е",    // ӗ
+	0x04db: "ә",    // ӛ
+	0x04dd: "ж",    // ӝ
+	0x04df: "з",    // ӟ
+	0x04e3: "и",    // ӣ
+	0x04e5: "и",    // ӥ
+	0x04e7: "о",    // ӧ
+	0x04eb: "ө",    // ӫ
+	0x04ed: "э",    // ӭ
+	0x04ef: "у",    // ӯ
+	0x04f1: "у",    // ӱ
+	0x04f3: "у",    // ӳ
+	0x04f5: "ч",    // ӵ
+	0x04f9: "ы",    // ӹ
+	0x0622: "ا",    // آ
+	0x0623: "ا",    // أ
+	0x0624: "و",    // ؤ
+	0x0625: "ا",    // إ
+	0x0626: "ي",    // ئ
+	0x0675: "اٴ",   // ٵ
+	0x0676: "وٴ",   // ٶ
+	0x0677: "ۇٴ",   // ٷ
+	0x0678: "يٴ",   // ٸ
+	0x06c0: "ە",    // ۀ
+	0x06c2: "ہ",    // ۂ
+	0x06d3: "ے",    // ۓ
+	0x0929: "न",    // ऩ
+	0x0931: "र",    // ऱ
+	0x0934: "ळ",    // ऴ
+	0x0958: "क",    // क़
+	0x0959: "ख",    // ख़
+	0x095a: "ग",    // ग़
+	0x095b: "ज",    // ज़
+	0x095c: "ड",    // ड़
+	0x095d: "ढ",    // ढ़
+	0x095e: "फ",    // फ़
+	0x095f: "य",    // य़
+	0x09cb: "ো",   // ো
+	0x09cc: "ৌ",   // ৌ
+	0x09dc: "ড",    // ড়
+	0x09dd: "ঢ",    // ঢ়
+	0x09df: "য",    // য়
+	0x0a33: "ਲ",    // ਲ਼
+	0x0a36: "ਸ",    // ਸ਼
+	0x0a59: "ਖ",    // ਖ਼
+	0x0a5a: "ਗ",    // ਗ਼
+	0x0a5b: "ਜ",    // ਜ਼
+	0x0a5e: "ਫ",    // ਫ਼
+	0x0b48: "େ",    // ୈ
+	0x0b4b: "ୋ",   // ୋ
+	0x0b4c: "ୌ",   // ୌ
+	0x0b5c: "ଡ",    // ଡ଼
+	0x0b5d: "ଢ",    // ଢ଼
+	0x0b94: "ஔ",   // ஔ
+	0x0bca: "ொ",   // ொ
+	0x0bcb: "ோ",   // ோ
+	0x0bcc: "ௌ",   // ௌ
+	0x0cc0: "ೕ",    // ೀ
+	0x0cc7: "ೕ",    // ೇ
+	0x0cc8: "ೖ",    // ೈ
+	0x0cca: "ೂ",    // ೊ
+	0x0ccb: "ೂೕ",   // ೋ
+	0x0d4a: "ൊ",   // ൊ
+	0x0d4b: "ോ",   // ോ
+	0x0d4c: "ൌ",   // ൌ
+	0x0dda: "ෙ",    // ේ
+	0x0ddc: "ො",   // ො
+	0x0ddd: "ො",   // ෝ
+	0x0dde: "ෞ",   // ෞ
+	0x0e33: "า",    // ำ
+	0x0eb3: "າ",    // ຳ
+	0x0edc: "ຫນ",   // ໜ
+	0x0edd: "ຫມ",   // ໝ
+	0x0f0c: "་",    // ༌
+	0x0f43: "ག",    // གྷ
+	0x0f4d: "ཌ",    // ཌྷ
+	0x0f52: "ད",    // དྷ
+	0x0f57: "བ",    // བྷ
+	0x0f5c: "ཛ",    // ཛྷ
+	0x0f69: "ཀ",    // ཀྵ
+	0x1026: "ဥ",    // ဦ
+	0x10fc: "ნ",    // ჼ
+	0x1b06: "ᬆ",   // ᬆ
+	0x1b08: "ᬈ",   // ᬈ
+	0x1b0a: "ᬊ",   // ᬊ
+	0x1b0c: "ᬌ",   // ᬌ
+	0x1b0e: "ᬎ",   // ᬎ
+	0x1b12: "ᬒ",   // ᬒ
+	0x1b3b: "ᬵ",    // ᬻ
+	0x1b3d: "ᬵ",    // ᬽ
+	0x1b40: "ᭀ",   // ᭀ
+	0x1b41: "ᭁ",   // ᭁ
+	0x1b43: "ᬵ",    // ᭃ
+	0x1d2c: "a",    // ᴬ
+	0x1d2d: "æ",    // ᴭ
+	0x1d2e: "b",    // ᴮ
+	0x1d30: "d",    // ᴰ
+	0x1d31: "e",    // ᴱ
+	0x1d32: "ǝ",    // ᴲ
+	0x1d33: "g",    // ᴳ
+	0x1d34: "h",    // ᴴ
+	0x1d35: "i",    // ᴵ
+	0x1d36: "j",    // ᴶ
+	0x1d37: "k",    // ᴷ
+	0x1d38: "l",    // ᴸ
+	0x1d39: "m",    // ᴹ
+	0x1d3a: "n",    // ᴺ
+	0x1d3c: "o",    // ᴼ
+	0x1d3d: "ȣ",    // ᴽ
+	0x1d3e: "p",    // ᴾ
+	0x1d3f: "r",    // ᴿ
+	0x1d40: "t",    // ᵀ
+	0x1d41: "u",    // ᵁ
+	0x1d42: "w",    // ᵂ
+	0x1d43: "a",    // ᵃ
+	0x1d44: "ɐ",    // ᵄ
+	0x1d45: "ɑ",    // ᵅ
+	0x1d46: "ᴂ",    // ᵆ
+	0x1d47: "b",    // ᵇ
+	0x1d48: "d",    // ᵈ
+	0x1d49: "e",    // ᵉ
+	0x1d4a: "ə",    // ᵊ
+	0x1d4b: "ɛ",    // ᵋ
+	0x1d4c: "ɜ",    // ᵌ
+	0x1d4d: "g",    // ᵍ
+	0x1d4f: "k",    // ᵏ
+	0x1d50: "m",    // ᵐ
+	0x1d51: "ŋ",    // ᵑ
+	0x1d52: "o",    // ᵒ
+	0x1d53: "ɔ",    // ᵓ
+	0x1d54: "ᴖ",    // ᵔ
+	0x1d55: "ᴗ",    // ᵕ
+	0x1d56: "p",    // ᵖ
+	0x1d57: "t",    // ᵗ
+	0x1d58: "u",    // ᵘ
+	0x1d59: "ᴝ",    // ᵙ
+	0x1d5a: "ɯ",    // ᵚ
+	0x1d5b: "v",    // ᵛ
+	0x1d5c: "ᴥ",    // ᵜ
+	0x1d5d: "β",    // ᵝ
+	0x1d5e: "γ",    // ᵞ
+	0x1d5f: "δ",    // ᵟ
+	0x1d60: "φ",    // ᵠ
+	0x1d61: "χ",    // ᵡ
+	0x1d62: "i",    // ᵢ
+	0x1d63: "r",    // ᵣ
+	0x1d64: "u",    // ᵤ
+	0x1d65: "v",    // ᵥ
+	0x1d66: "β",    // ᵦ
+	0x1d67: "γ",    // ᵧ
+	0x1d68: "ρ",    // ᵨ
+	0x1d69: "φ",    // ᵩ
+	0x1d6a: "χ",    // ᵪ
+	0x1d78: "н",    // ᵸ
+	0x1d9b: "ɒ",    // ᶛ
+	0x1d9c: "c",    // ᶜ
+	0x1d9d: "ɕ",    // ᶝ
+	0x1d9e: "ð",    // ᶞ
+	0x1d9f: "ɜ",    // ᶟ
+	0x1da0: "f",    // ᶠ
+	0x1da1: "ɟ",    // ᶡ
+	0x1da2: "ɡ",    // ᶢ
+	0x1da3: "ɥ",    // ᶣ
+	0x1da4: "ɨ",    // ᶤ
+	0x1da5: "ɩ",    // ᶥ
+	0x1da6: "ɪ",    // ᶦ
+	0x1da7: "ᵻ",    // ᶧ
+	0x1da8: "ʝ",    // ᶨ
+	0x1da9: "ɭ",    // ᶩ
+	0x1daa: "ᶅ",    // ᶪ
+	0x1dab: "ʟ",    // ᶫ
+	0x1dac: "ɱ",    // ᶬ
+	0x1dad: "ɰ",    // ᶭ
+	0x1dae: "ɲ",    // ᶮ
+	0x1daf: "ɳ",    // ᶯ
+	0x1db0: "ɴ",    // ᶰ
+	0x1db1: "ɵ",    // ᶱ
+	0x1db2: "ɸ",    // ᶲ
+	0x1db3: "ʂ",    // ᶳ
+	0x1db4: "ʃ",    // ᶴ
+	0x1db5: "ƫ",    // ᶵ
+	0x1db6: "ʉ",    // ᶶ
+	0x1db7: "ʊ",    // ᶷ
+	0x1db8: "ᴜ",    // ᶸ
+	0x1db9: "ʋ",    // ᶹ
+	0x1dba: "ʌ",    // ᶺ
+	0x1dbb: "z",    // ᶻ
+	0x1dbc: "ʐ",    // ᶼ
+	0x1dbd: "ʑ",    // ᶽ
+	0x1dbe: "ʒ",    // ᶾ
+	0x1dbf: "θ",    // ᶿ
+	0x1e01: "a",    // ḁ
+	0x1e03: "b",    // ḃ
+	0x1e05: "b",    // ḅ
+	0x1e07: "b",    // ḇ
+	0x1e09: "c",    // ḉ
+	0x1e0b: "d",    // ḋ
+	0x1e0d: "d",    // ḍ
+	0x1e0f: "d",    // ḏ
+	0x1e11: "d",    // ḑ
+	0x1e13: "d",    // ḓ
+	0x1e15: "e",    // ḕ
+	0x1e17: "e",    // ḗ
+	0x1e19: "e",    // ḙ
+	0x1e1b: "e",    // ḛ
+	0x1e1d: "e",    // ḝ
+	0x1e1f: "f",    // ḟ
+	0x1e21: "g",    // ḡ
+	0x1e23: "h",    // ḣ
+	0x1e25: "h",    // ḥ
+	0x1e27: "h",    // ḧ
+	0x1e29: "h",    // ḩ
+	0x1e2b: "h",    // ḫ
+	0x1e2d: "i",    // ḭ
+	0x1e2f: "i",    // ḯ
+	0x1e31: "k",    // ḱ
+	0x1e33: "k",    // ḳ
+	0x1e35: "k",    // ḵ
+	0x1e37: "l",    // ḷ
+	0x1e39: "l",    // ḹ
+	0x1e3b: "l",    // ḻ
+	0x1e3d: "l",    // ḽ
+	0x1e3f: "m",    // ḿ
+	0x1e41: "m",    // ṁ
+	0x1e43: "m",    // ṃ
+	0x1e45: "n",    // ṅ
+	0x1e47: "n",    // ṇ
+	0x1e49: "n",    // ṉ
+	0x1e4b: "n",    // ṋ
+	0x1e4d: "o",    // ṍ
+	0x1e4f: "o",    // ṏ
+	0x1e51: "o",    // ṑ
+	0x1e53: "o",    // ṓ
+	0x1e55: "p",    // ṕ
+	0x1e57: "p",    // ṗ
+	0x1e59: "r",    // ṙ
+	0x1e5b: "r",    // ṛ
+	0x1e5d: "r",    // ṝ
+	0x1e5f: "r",    // ṟ
+	0x1e61: "s",    // ṡ
+	0x1e63: "s",    // ṣ
+	0x1e65: "s",    // ṥ
+	0x1e67: "s",    // ṧ
+	0x1e69: "s",    // ṩ
+	0x1e6b: "t",    // ṫ
+	0x1e6d: "t",    // ṭ
+	0x1e6f: "t",    // ṯ
+	0x1e71: "t",    // ṱ
+	0x1e73: "u",    // ṳ
+	0x1e75: "u",    // ṵ
+	0x1e77: "u",    // ṷ
+	0x1e79: "u",    // ṹ
+	0x1e7b: "u",    // ṻ
+	0x1e7d: "v",    // ṽ
+	0x1e7f: "v",    // ṿ
+	0x1e81: "w",    // ẁ
+	0x1e83: "w",    // ẃ
+	0x1e85: "w",    // ẅ
+	0x1e87: "w",    // ẇ
+	0x1e89: "w",    // ẉ
+	0x1e8b: "x",    // ẋ
+	0x1e8d: "x",    // ẍ
+	0x1e8f: "y",    // ẏ
+	0x1e91: "z",    // ẑ
+	0x1e93: "z",    // ẓ
+	0x1e95: "z",    // ẕ
+	0x1ea1: "a",    // ạ
+	0x1ea3: "a",    // ả
+	0x1ea5: "a",    // ấ
+	0x1ea7: "a",    // ầ
+	0x1ea9: "a",    // ẩ
+	0x1eab: "a",    // ẫ
+	0x1ead: "a",    // ậ
+	0x1eaf: "a",    // ắ
+	0x1eb1: "a",    // ằ
+	0x1eb3: "a",    // ẳ
+	0x1eb5: "a",    // ẵ
+	0x1eb7: "a",    // ặ
+	0x1eb9: "e",    // ẹ
+	0x1ebb: "e",    // ẻ
+	0x1ebd: "e",    // ẽ
+	0x1ebf: "e",    // ế
+	0x1ec1: "e",    // ề
+	0x1ec3: "e",    // ể
+	0x1ec5: "e",    // ễ
+	0x1ec7: "e",    // ệ
+	0x1ec9: "i",    // ỉ
+	0x1ecb: "i",    // ị
+	0x1ecd: "o",    // ọ
+	0x1ecf: "o",    // ỏ
+	0x1ed1: "o",    // ố
+	0x1ed3: "o",    // ồ
+	0x1ed5: "o",    // ổ
+	0x1ed7: "o",    // ỗ
+	0x1ed9: "o",    // ộ
+	0x1edb: "o",    // ớ
+	0x1edd: "o",    // ờ
+	0x1edf: "o",    // ở
+	0x1ee1: "o",    // ỡ
+	0x1ee3: "o",    // ợ
+	0x1ee5: "u",    // ụ
+	0x1ee7: "u",    // ủ
+	0x1ee9: "u",    // ứ
+	0x1eeb: "u",    // ừ
+	0x1eed: "u",    // ử
+	0x1eef: "u",    // ữ
+	0x1ef1: "u",    // ự
+	0x1ef3: "y",    // ỳ
+	0x1ef5: "y",    // ỵ
+	0x1ef7: "y",    // ỷ
+	0x1ef9: "y",    // ỹ
+	0x1f00: "α",    // ἀ
+	0x1f01: "α",    // ἁ
+	0x1f02: "α",    // ἂ
+	0x1f03: "α",    // ἃ
+	0x1f04: "α",    // ἄ
+	0x1f05: "α",    // ἅ
+	0x1f06: "α",    // ἆ
+	0x1f07: "α",    // ἇ
+	0x1f10: "ε",    // ἐ
+	0x1f11: "ε",    // ἑ
+	0x1f12: "ε",    // ἒ
+	0x1f13: "ε",    // ἓ
+	0x1f14: "ε",    // ἔ
+	0x1f15: "ε",    // ἕ
+	0x1f20: "η",    // ἠ
+	0x1f21: "η",    // ἡ
+	0x1f22: "η",    // ἢ
+	0x1f23: "η",    // ἣ
+	0x1f24: "η",    // ἤ
+	0x1f25: "η",    // ἥ
+	0x1f26: "η",    // ἦ
+	0x1f27: "η",    // ἧ
+	0x1f30: "ι",    // ἰ
+	0x1f31: "ι",    // ἱ
+	0x1f32: "ι",    // ἲ
+	0x1f33: "ι",    // ἳ
+	0x1f34: "ι",    // ἴ
+	0x1f35: "ι",    // ἵ
+	0x1f36: "ι",    // ἶ
+	0x1f37: "ι",    // ἷ
+	0x1f40: "ο",    // ὀ
+	0x1f41: "ο",    // ὁ
+	0x1f42: "ο",    // ὂ
+	0x1f43: "ο",    // ὃ
+	0x1f44: "ο",    // ὄ
+	0x1f45: "ο",    // ὅ
+	0x1f51: "υ",    // ὑ
+	0x1f53: "υ",    // ὓ
+	0x1f55: "υ",    // ὕ
+	0x1f57: "υ",    // ὗ
+	0x1f60: "ω",    // ὠ
+	0x1f61: "ω",    // ὡ
+	0x1f62: "ω",    // ὢ
+	0x1f63: "ω",    // ὣ
+	0x1f64: "ω",    // ὤ
+	0x1f65: "ω",    // ὥ
+	0x1f66: "ω",    // ὦ
+	0x1f67: "ω",    // ὧ
+	0x1f70: "α",    // ὰ
+	0x1f71: "α",    // ά
+	0x1f72: "ε",    // ὲ
+	0x1f73: "ε",    // έ
+	0x1f74: "η",    // ὴ
+	0x1f75: "η",    // ή
+	0x1f76: "ι",    // ὶ
+	0x1f77: "ι",    // ί
+	0x1f78: "ο",    // ὸ
+	0x1f79: "ο",    // ό
+	0x1f7a: "υ",    // ὺ
+	0x1f7b: "υ",    // ύ
+	0x1f7c: "ω",    // ὼ
+	0x1f7d: "ω",    // ώ
+	0x1fb0: "α",    // ᾰ
+	0x1fb1: "α",    // ᾱ
+	0x1fbd: " ",    // ᾽
+	0x1fbf: " ",    // ᾿
+	0x1fc0: " ",    // ῀
+	0x1fc1: " ",    // ῁
+	0x1fcd: " ",    // ῍
+	0x1fce: " ",    // ῎
+	0x1fcf: " ",    // ῏
+	0x1fd0: "ι",    // ῐ
+	0x1fd1: "ι",    // ῑ
+	0x1fdd: " ",    // ῝
+	0x1fde: " ",    // ῞
+	0x1fdf: " ",    // ῟
+	0x1fe0: "υ",    // ῠ
+	0x1fe1: "υ",    // ῡ
+	0x1fe5: "ρ",    // ῥ
+	0x1fed: " ",    // ῭
+	0x1fee: " ",    // ΅
+	0x1fef: "`",    // `
+	0x1ffd: " ",    // ´
+	0x1ffe: " ",    // ῾
+	0x2011: "‐",    // ‑
+	0x2017: " ",    // ‗
+	0x2024: ".",    // ․
+	0x2025: "..",   // ‥
+	0x2026: "...",  // …
+	0x2033: "′′",   // ″
+	0x2034: "′′′",  // ‴
+	0x2036: "‵‵",   // ‶
+	0x2037: "‵‵‵",  // ‷
+	0x203c: "!!",   // ‼
+	0x203e: " ",    // ‾
+	0x2047: "??",   // ⁇
+	0x2048: "?!",   // ⁈
+	0x2049: "!?",   // ⁉
+	0x2057: "′′′′", // ⁗
+	0x2070: "0",    // ⁰
+	0x2071: "i",    // ⁱ
+	0x2074: "4",    // ⁴
+	0x2075: "5",    // ⁵
+	0x2076: "6",    // ⁶
+	0x2077: "7",    // ⁷
+	0x2078: "8",    // ⁸
+	0x2079: "9",    // ⁹
+	0x207a: "+",    // ⁺
+	0x207b: "−",    // ⁻
+	0x207c: "=",    // ⁼
+	0x207d: "(",    // ⁽
+	0x207e: ")",    // ⁾
+	0x207f: "n",    // ⁿ
+	0x2080: "0",    // ₀
+	0x2081: "1",    // ₁
+	0x2082: "2",    // ₂
+	0x2083: "3",    // ₃
+	0x2084: "4",    // ₄
+	0x2085: "5",    // ₅
+	0x2086: "6",    // ₆
+	0x2087: "7",    // ₇
+	0x2088: "8",    // ₈
+	0x2089: "9",    // ₉
+	0x208a: "+",    // ₊
+	0x208b: "−",    // ₋
+	0x208c: "=",    // ₌
+	0x208d: "(",    // ₍
+	0x208e: ")",    // ₎
+	0x2090: "a",    // ₐ
+	0x2091: "e",    // ₑ
+	0x2092: "o",    // ₒ
+	0x2093: "x",    // ₓ
+	0x2094: "ə",    // ₔ
+	0x2095: "h",    // ₕ
+	0x2096: "k",    // ₖ
+	0x2097: "l",    // ₗ
+	0x2098: "m",    // ₘ
+	0x2099: "n",    // ₙ
+	0x209a: "p",    // ₚ
+	0x209b: "s",    // ₛ
+	0x209c: "t",    // ₜ
+	0x20a8: "rs",   // ₨
+	0x2100: "a/c",  // ℀
+	0x2101: "a/s",  // ℁
+	0x2102: "c",    // ℂ
+	0x2103: "°c",   // ℃
+	0x2105: "c/o",  // ℅
+	0x2106: "c/u",  // ℆
+	0x2107: "ɛ",    // ℇ
+	0x2109: "°f",   // ℉
+	0x210a: "g",    // ℊ
+	0x210b: "h",    // ℋ
+	0x210c: "h",    // ℌ
+	0x210d: "h",    // ℍ
+	0x210e: "h",    // ℎ
+	0x210f: "ħ",    // ℏ
+	0x2110: "i",    // ℐ
+	0x2111: "i",    // ℑ
+	0x2112: "l",    // ℒ
+	0x2113: "l",    // ℓ
+	0x2115: "n",    // ℕ
+	0x2116: "no",   // №
+	0x2119: "p",    // ℙ
+	0x211a: "q",    // ℚ
+	0x211b: "r",    // ℛ
+	0x211c: "r",    // ℜ
+	0x211d: "r",    // ℝ
+	0x2120: "sm",   // ℠
+	0x2121: "tel",  // ℡
+	0x2122: "tm",   // ™
+	0x2124: "z",    // ℤ
+	0x2128: "z",    // ℨ
+	0x212c: "b",    // ℬ
+	0x212d: "c",    // ℭ
+	0x212f: "e",    // ℯ
+	0x2130: "e",    // ℰ
+	0x2131: "f",    // ℱ
+	0x2133: "m",    // ℳ
+	0x2134: "o",    // ℴ
+	0x2135: "א",    // ℵ
+	0x2136: "ב",    // ℶ
+	0x2137: "ג",    // ℷ
+	0x2138: "ד",    // ℸ
+	0x2139: "i",    // ℹ
+	0x213b: "fax",  // ℻
+	0x213c: "π",    // ℼ
+	0x213d: "γ",    // ℽ
+	0x213e: "γ",    // ℾ
+	0x213f: "π",    // ℿ
+	0x2140: "∑",    // ⅀
+	0x2145: "d",    // ⅅ
+	0x2146: "d",    // ⅆ
+	0x2147: "e",    // ⅇ
+	0x2148: "i",    // ⅈ
+	0x2149: "j",    // ⅉ
+	0x2150: "1⁄7",  // ⅐
+	0x2151: "1⁄9",  // ⅑
+	0x2152: "1⁄10", // ⅒
+	0x2153: "1⁄3",  // ⅓
+	0x2154: "2⁄3",  // ⅔
+	0x2155: "1⁄5",  // ⅕
+	0x2156: "2⁄5",  // ⅖
+	0x2157: "3⁄5",  // ⅗
+	0x2158: "4⁄5",  // ⅘
+	0x2159: "1⁄6",  // ⅙
+	0x215a: "5⁄6",  // ⅚
+	0x215b: "1⁄8",  // ⅛
+	0x215c: "3⁄8",  // ⅜
+	0x215d: "5⁄8",  // ⅝
+	0x215e: "7⁄8",  // ⅞
+	0x215f: "1⁄",   // ⅟
+	0x2170: "i",    // ⅰ
+	0x2171: "ii",   // ⅱ
+	0x2172: "iii",  // ⅲ
+	0x2173: "iv",   // ⅳ
+	0x2174: "v",    // ⅴ
+	0x2175: "vi",   // ⅵ
+	0x2176: "vii",  // ⅶ
+	0x2177: "viii", // ⅷ
+	0x2178: "ix",   // ⅸ
+	0x2179: "x",    // ⅹ
+	0x217a: "xi",   // ⅺ
+	0x217b: "xii",  // ⅻ
+	0x217c: "l",    // ⅼ
+	0x217d: "c",    // ⅽ
+	0x217e: "d",    // ⅾ
+	0x217f: "m",    // ⅿ
+	0x2189: "0⁄3",  // ↉
+	0x219a: "←",    // ↚
+	0x219b: "→",    // ↛
+	0x21ae: "↔",    // ↮
+	0x21cd: "⇐",    // ⇍
+	0x21ce: "⇔",    // ⇎
+	0x21cf: "⇒",    // ⇏
+	0x2204: "∃",    // ∄
+	0x2209: "∈",    // ∉
+	0x220c: "∋",    // ∌
+	0x2224: "∣",    // ∤
+	0x2226: "∥",    // ∦
+	0x222c: "∫∫",   // ∬
+	0x222d: "∫∫∫",  // ∭
+	0x222f: "∮∮",   // ∯
+	0x2230: "∮∮∮",  // ∰
+	0x2241: "∼",    // ≁
+	0x2244: "≃",    // ≄
+	0x2247: "≅",    // ≇
+	0x2249: "≈",    // ≉
+	0x2260: "=",    // ≠
+	0x2262: "≡",    // ≢
+	0x226d: "≍",    // ≭
+	0x226e: "<",    // ≮
+	0x226f: ">",    // ≯
+	0x2270: "≤",    // ≰
+	0x2271: "≥",    // ≱
+	0x2274: "≲",    // ≴
+	0x2275: "≳",    // ≵
+	0x2278: "≶",    // ≸
+	0x2279: "≷",    // ≹
+	0x2280: "≺",    // ⊀
+	0x2281: "≻",    // ⊁
+	0x2284: "⊂",    // ⊄
+	0x2285: "⊃",    // ⊅
+	0x2288: "⊆",    // ⊈
+	0x2289: "⊇",    // ⊉
+	0x22ac: "⊢",    // ⊬
+	0x22ad: "⊨",    // ⊭
+	0x22ae: "⊩",    // ⊮
+	0x22af: "⊫",    // ⊯
+	0x22e0: "≼",    // ⋠
+	0x22e1: "≽",    // ⋡
+	0x22e2: "⊑",    // ⋢
+	0x22e3: "⊒",    // ⋣
+	0x22ea: "⊲",    // ⋪
+	0x22eb: "⊳",    // ⋫
+	0x22ec: "⊴",    // ⋬
+	0x22ed: "⊵",    // ⋭
+	0x2329: "〈",    // 〈
+	0x232a: "〉",    // 〉
+	0x2460: "1",    // ①
+	0x2461: "2",    // ②
+	0x2462: "3",    // ③
+	0x2463: "4",    // ④
+	0x2464: "5",    // ⑤
+	0x2465: "6",    // ⑥
+	0x2466: "7",    // ⑦
+	0x2467: "8",    // ⑧
+	0x2468: "9",    // ⑨
+	0x2469: "10",   // ⑩
+	0x246a: "11",   // ⑪
+	0x246b: "12",   // ⑫
+	0x246c: "13",   // ⑬
+	0x246d: "14",   // ⑭
+	0x246e: "15",   // ⑮
+	0x246f: "16",   // ⑯
+	0x2470: "17",   // ⑰
+	0x2471: "18",   // ⑱
+	0x2472: "19",   // ⑲
+	0x2473: "20",   // ⑳
+	0x2474: "(1)",  // ⑴
+	0x2475: "(2)",  // ⑵
+	0x2476: "(3)",  // ⑶
+	0x2477: "(4)",  // ⑷
+	0x2478: "(5)",  // ⑸
+	0x2479: "(6)",  // ⑹
+	0x247a: "(7)",  // ⑺
+	0x247b: "(8)",  // ⑻
+	0x247c: "(9)",  // ⑼
+	0x247d: "(10)", // ⑽
+	0x247e: "(11)", // ⑾
+	0x247f: "(12)", // ⑿
+	0x2480: "(13)", // ⒀
+	0x2481: "(14)", // ⒁
+	0x2482: "(15)", // ⒂
+	0x2483: "(16)", // ⒃
+	0x2484: "(17)", // ⒄
+	0x2485: "(18)", // ⒅
+	0x2486: "(19)", // ⒆
+	0x2487: "(20)", // ⒇
+	0x2488: "1.",   // ⒈
+	0x2489: "2.",   // ⒉
+	0x248a: "3.",   // ⒊
+	0x248b: "4.",   // ⒋
+	0x248c: "5.",   // ⒌
+	0x248d: "6.",   // ⒍
+	0x248e: "7.",   // ⒎
+	0x248f: "8.",   // ⒏
+	0x2490: "9.",   // ⒐
+	0x2491: "10.",  // ⒑
+	0x2492: "11.",  // ⒒
+	0x2493: "12.",  // ⒓
+	0x2494: "13.",  // ⒔
+	0x2495: "14.",  // ⒕
+	0x2496: "15.",  // ⒖
+	0x2497: "16.",  // ⒗
+	0x2498: "17.",  // ⒘
+	0x2499: "18.",  // ⒙
+	0x249a: "19.",  // ⒚
+	0x249b: "20.",  // ⒛
+	0x249c: "(a)",  // ⒜
+	0x249d: "(b)",  // ⒝
+	0x249e: "(c)",  // ⒞
+	0x249f: "(d)",  // ⒟
+	0x24a0: "(e)",  // ⒠
+	0x24a1: "(f)",  // ⒡
+	0x24a2: "(g)",  // ⒢
+	0x24a3: "(h)",  // ⒣
+	0x24a4: "(i)",  // ⒤
+	0x24a5: "(j)",  // ⒥
+	0x24a6: "(k)",  // ⒦
+	0x24a7: "(l)",  // ⒧
+	0x24a8: "(m)",  // ⒨
+	0x24a9: "(n)",  // ⒩
+	0x24aa: "(o)",  // ⒪
+	0x24ab: "(p)",  // ⒫
+	0x24ac: "(q)",  // ⒬
+	0x24ad: "(r)",  // ⒭
+	0x24ae: "(s)",  // ⒮
+	0x24af: "(t)",  // ⒯
+	0x24b0: "(u)",  // ⒰
+	0x24b1: "(v)",  // ⒱
+	0x24b2: "(w)",  // ⒲
+	0x24b3: "(x)",  // ⒳
+	0x24b4: "(y)",  // ⒴
+	0x24b5: "(z)",  // ⒵
+	0x24d0: "a",    // ⓐ
+	0x24d1: "b",    // ⓑ
+	0x24d2: "c",    // ⓒ
+	0x24d3: "d",    // ⓓ
+	0x24d4: "e",    // ⓔ
+	0x24d5: "f",    // ⓕ
+	0x24d6: "g",    // ⓖ
+	0x24d7: "h",    // ⓗ
+	0x24d8: "i",    // ⓘ
+	0x24d9: "j",    // ⓙ
+	0x24da: "k",    // ⓚ
+	0x24db: "l",    // ⓛ
+	0x24dc: "m",    // ⓜ
+	0x24dd: "n",    // ⓝ
+	0x24de: "o",    // ⓞ
+	0x24df: "p",    // ⓟ
+	0x24e0: "q",    // ⓠ
+	0x24e1: "r",    // ⓡ
+	0x24e2: "s",    // ⓢ
+	0x24e3: "t",    // ⓣ
+	0x24e4: "u",    // ⓤ
+	0x24e5: "v",    // ⓥ
+	0x24e6: "w",    // ⓦ
+	0x24e7: "x",    // ⓧ
+	0x24e8: "y",    // ⓨ
+	0x24e9: "z",    // ⓩ
+	0x24ea: "0",    // ⓪
+	0xff01: "!",    // ！
+	0xff02: "\"",   // ＂
+	0xff03: "#",    // ＃
+	0xff04: "$",    // ＄
+	0xff05: "%",    // ％
+	0xff06: "&",    // ＆
+	0xff07: "'",    // ＇
+	0xff08: "(",    // （
+	0xff09: ")",    // ）
+	0xff0a: "*",    // ＊
+	0xff0b: "+",    // ＋
+	0xff0c: ",",    // ，
+	0xff0d: "-",    // －
+	0xff0e: ".",    // ．
+	0xff0f: "/",    // ／
+	0xff10: "0",    // ０
+	0xff11: "1",    // １
+	0xff12: "2",    // ２
+	0xff13: "3",    // ３
+	0xff14: "4",    // ４
+	0xff15: "5",    // ５
+	0xff16: "6",    // ６
+	0xff17: "7",    // ７
+	0xff18: "8",    // ８
+	0xff19: "9",    // ９
+	0xff1a: ":",    // ：
+	0xff1b: ";",    // ；
+	0xff1c: "<",    // ＜
+	0xff1d: "=",    // ＝
+	0xff1e: ">",    // ＞
+	0xff1f: "?",    // ？
+	0xff20: "@",    // ＠
+	0xff3b: "[",    // ［
+	0xff3c: "\\",   // ＼
+	0xff3d: "]",    // ］
+	0xff3e: "^",    // ＾
+	0xff3f: "_",    // ＿
+	0xff40: "`",    // ｀
+	0xff41: "a",    // ａ
+	0xff42: "b",    // ｂ
+	0xff43: "c",    // ｃ
+	0xff44: "d",    // ｄ
+	0xff45: "e",    // ｅ
+	0xff46: "f",    // ｆ
+	0xff47: "g",    // ｇ
+	0xff48: "h",    // ｈ
+	0xff49: "i",    // ｉ
+	0xff4a: "j",    // ｊ
+	0xff4b: "k",    // ｋ
+	0xff4c: "l",    // ｌ
+	0xff4d: "m",    // ｍ
+	0xff4e: "n",    // ｎ
+	0xff4f: "o",    // ｏ
+	0xff50: "p",    // ｐ
+	0xff51: "q",    // ｑ
+	0xff52: "r",    // ｒ
+	0xff53: "s",    // ｓ
+	0xff54: "t",    // ｔ
+	0xff55: "u",    // ｕ
+	0xff56: "v",    // ｖ
+	0xff57: "w",    // ｗ
+	0xff58: "x",    // ｘ
+	0xff59: "y",    // ｙ
+	0xff5a: "z",    // ｚ
+	0xff5b: "{",    // ｛
+	0xff5c: "|",    // ｜
+	0xff5d: "}",    // ｝
+	0xff5e: "~",    // ～
+	0xff5f: "⦅",    // ｟
+	0xff60: "⦆",    // ｠
+	0xff61: "。",    // ｡
+	0xff62: "「",    // ｢
+	0xff63: "」",    // ｣
+	0xff64: "、",    // ､
+	0xff65: "・",    // ･
+	0xff66: "ヲ",    // ｦ
+	0xff67: "ァ",    // ｧ
+	0xff68: "ィ",    // ｨ
+	0xff69: "ゥ",    // ｩ
+	0xff6a: "ェ",    // ｪ
+	0xff6b: "ォ",    // ｫ
+	0xff6c: "ャ",    // ｬ
+	0xff6d: "ュ",    // ｭ
+	0xff6e: "ョ",    // ｮ
+	0xff6f: "ッ",    // ｯ
+	0xff70: "ー",    // ｰ
+	0xff71: "ア",    // ｱ
+	0xff72: "イ",    // ｲ
+	0xff73: "ウ",    // ｳ
+	0xff74: "エ",    // ｴ
+	0xff75: "オ",    // ｵ
+	0xff76: "カ",    // ｶ
+	0xff77: "キ",    // ｷ
+	0xff78: "ク",    // ｸ
+	0xff79: "ケ",    // ｹ
+	0xff7a: "コ",    // ｺ
+	0xff7b: "サ",    // ｻ
+	0xff7c: "シ",    // ｼ
+	0xff7d: "ス",    // ｽ
+	0xff7e: "セ",    // ｾ
+	0xff7f: "ソ",    // ｿ
+	0xff80: "タ",    // ﾀ
+	0xff81: "チ",    // ﾁ
+	0xff82: "ツ",    // ﾂ
+	0xff83: "テ",    // ﾃ
+	0xff84: "ト",    // ﾄ
+	0xff85: "ナ",    // ﾅ
+	0xff86: "ニ",    // ﾆ
+	0xff87: "ヌ",    // ﾇ
+	0xff88: "ネ",    // ﾈ
+	0xff89: "ノ",    // ﾉ
+	0xff8a: "ハ",    // ﾊ
+	0xff8b: "ヒ",    // ﾋ
+	0xff8c: "フ",    // ﾌ
+	0xff8d: "ヘ",    // ﾍ
+	0xff8e: "ホ",    // ﾎ
+	0xff8f: "マ",    // ﾏ
+	0xff90: "ミ",    // ﾐ
+	0xff91: "ム",    // ﾑ
+	0xff92: "メ",    // ﾒ
+	0xff93: "モ",    // ﾓ
+	0xff94: "ヤ",    // ﾔ
+	0xff95: "ユ",    // ﾕ
+	0xff96: "ヨ",    // ﾖ
+	0xff97: "ラ",    // ﾗ
+	0xff98: "リ",    // ﾘ
+	0xff99: "ル",    // ﾙ
+	0xff9a: "レ",    // ﾚ
+	0xff9b: "ロ",    // ﾛ
+	0xff9c: "ワ",    // ﾜ
+	0xff9d: "ン",    // ﾝ
+	0xff9e: "",     // ﾞ
+	0xff9f: "",     // ﾟ
+	0xffa0: "ᅠ",    // ﾠ
+	0xffa1: "ᄀ",    // ﾡ
+	0xffa2: "ᄁ",    // ﾢ
+	0xffa3: "ᆪ",    // ﾣ
+	0xffa4: "ᄂ",    // ﾤ
+	0xffa5: "ᆬ",    // ﾥ
+	0xffa6: "ᆭ",    // ﾦ
+	0xffa7: "ᄃ",    // ﾧ
+	0xffa8: "ᄄ",    // ﾨ
+	0xffa9: "ᄅ",    // ﾩ
+	0xffaa: "ᆰ",    // ﾪ
+	0xffab: "ᆱ",    // ﾫ
+	0xffac: "ᆲ",    // ﾬ
+	0xffad: "ᆳ",    // ﾭ
+	0xffae: "ᆴ",    // ﾮ
+	0xffaf: "ᆵ",    // ﾯ
+	0xffb0: "ᄚ",    // ﾰ
+	0xffb1: "ᄆ",    // ﾱ
+	0xffb2: "ᄇ",    // ﾲ
+	0xffb3: "ᄈ",    // ﾳ
+	0xffb4: "ᄡ",    // ﾴ
+	0xffb5: "ᄉ",    // ﾵ
+	0xffb6: "ᄊ",    // ﾶ
+	0xffb7: "ᄋ",    // ﾷ
+	0xffb8: "ᄌ",    // ﾸ
+	0xffb9: "ᄍ",    // ﾹ
+	0xffba: "ᄎ",    // ﾺ
+	0xffbb: "ᄏ",    // ﾻ
+	0xffbc: "ᄐ",    // ﾼ
+	0xffbd: "ᄑ",    // ﾽ
+	0xffbe: "ᄒ",    // ﾾ
+	0xffc2: "ᅡ",    // ￂ
+	0xffc3: "ᅢ",    // ￃ
+	0xffc4: "ᅣ",    // ￄ
+	0xffc5: "ᅤ",    // ￅ
+	0xffc6: "ᅥ",    // ￆ
+	0xffc7: "ᅦ",    // ￇ
+	0xffca: "ᅧ",    // ￊ
+	0xffcb: "ᅨ",    // ￋ
+	0xffcc: "ᅩ",    // ￌ
+	0xffcd: "ᅪ",    // ￍ
+	0xffce: "ᅫ",    // ￎ
+	0xffcf: "ᅬ",    // ￏ
+	0xffd2: "ᅭ",    // ￒ
+	0xffd3: "ᅮ",    // ￓ
+	0xffd4: "ᅯ",    // ￔ
+	0xffd5: "ᅰ",    // ￕ
+	0xffd6: "ᅱ",    // ￖ
+	0xffd7: "ᅲ",    // ￗ
+	0xffda: "ᅳ",    // ￚ
+	0xffdb: "ᅴ",    // ￛ
+	0xffdc: "ᅵ",    // ￜ
+	0xffe0: "¢",    // ￠
+	0xffe1: "£",    // ￡
+	0xffe2: "¬",    // ￢
+	0xffe3: " ",    // ￣
+	0xffe4: "¦",    // ￤
+	0xffe5: "¥",    // ￥
+	0xffe6: "₩",    // ￦
+	0xffe8: "│",    // ￨
+	0xffe9: "←",    // ￩
+	0xffea: "↑",    // ￪
+	0xffeb: "→",    // ￫
+	0xffec: "↓",    // ￬
+	0xffed: "■",    // ￭
+	0xffee: "○",    // ￮
+}
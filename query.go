@@ -0,0 +1,446 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ошибка разбора query с позицией (номер символа с 1)
+type QuerySyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("query syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// Узел разобранного запроса; eval возвращает индексы подходящих
+// пользователей снимка по возрастанию
+type queryNode interface {
+	eval(ev *queryEval) []int
+}
+
+// Контекст вычисления запроса над снимком
+type queryEval struct {
+	ds  *dataset
+	idx *invertedIndex // индекс выбранного режима сравнения
+}
+
+type andNode struct{ left, right queryNode }
+
+type orNode struct{ left, right queryNode }
+
+type notNode struct{ child queryNode }
+
+// Слово, префикс или фраза в Name и/или About
+type textNode struct {
+	field  string // "name", "about" или пусто для обоих полей
+	text   string // исходный текст, преобразуется под режим сравнения при вычислении
+	prefix bool
+}
+
+// Сравнение числового поля
+type numberNode struct {
+	field string
+	op    string
+	value int
+}
+
+// Точное (без учёта регистра) совпадение строкового поля
+type keywordNode struct {
+	field string
+	value string
+}
+
+// Поля, доступные в запросе через "поле:значение"
+const (
+	queryFieldText = iota
+	queryFieldNumber
+	queryFieldKeyword
+	queryFieldBool
+)
+
+var queryFields = map[string]int{
+	"name":           queryFieldText,
+	"about":          queryFieldText,
+	"id":             queryFieldNumber,
+	"age":            queryFieldNumber,
+	"gender":         queryFieldKeyword,
+	"company":        queryFieldKeyword,
+	"email":          queryFieldKeyword,
+	"eye_color":      queryFieldKeyword,
+	"favorite_fruit": queryFieldKeyword,
+	"is_active":      queryFieldBool,
+}
+
+func (n *andNode) eval(ev *queryEval) []int {
+	left := n.left.eval(ev)
+	if len(left) == 0 {
+		return nil
+	}
+	return intersectSorted(left, n.right.eval(ev))
+}
+
+func (n *orNode) eval(ev *queryEval) []int {
+	return unionSorted([][]int{n.left.eval(ev), n.right.eval(ev)})
+}
+
+func (n *notNode) eval(ev *queryEval) []int {
+	excluded := n.child.eval(ev)
+	result := make([]int, 0, len(ev.ds.users)-len(excluded))
+	for i, k := 0, 0; i < len(ev.ds.users); i++ {
+		if k < len(excluded) && excluded[k] == i {
+			k++
+			continue
+		}
+		result = append(result, i)
+	}
+	return result
+}
+
+func (n *textNode) eval(ev *queryEval) []int {
+	tokens := tokenize(ev.idx.transform(n.text))
+	if len(tokens) == 0 {
+		return nil
+	}
+	clause := textClause{tokens: tokens, prefix: n.prefix}
+	candidates := ev.idx.searchClause(clause)
+	if n.field == "" {
+		return candidates
+	}
+
+	fieldTokens := ev.idx.name
+	if n.field == "about" {
+		fieldTokens = ev.idx.about
+	}
+	result := candidates[:0:0]
+	for _, i := range candidates {
+		if containsPhrase(fieldTokens[i], clause) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func (n *numberNode) eval(ev *queryEval) []int {
+	return ev.scan(func(u *UserServer) bool {
+		value := u.Age
+		if n.field == "id" {
+			value = u.ID
+		}
+		switch n.op {
+		case ">":
+			return value > n.value
+		case ">=":
+			return value >= n.value
+		case "<":
+			return value < n.value
+		case "<=":
+			return value <= n.value
+		}
+		return value == n.value
+	})
+}
+
+func (n *keywordNode) eval(ev *queryEval) []int {
+	return ev.scan(func(u *UserServer) bool {
+		var value string
+		switch n.field {
+		case "gender":
+			value = u.Gender
+		case "company":
+			value = u.Company
+		case "email":
+			value = u.Email
+		case "eye_color":
+			value = u.EyeColor
+		case "favorite_fruit":
+			value = u.FavoriteFruit
+		case "is_active":
+			value = strconv.FormatBool(u.IsActive)
+		}
+		return strings.EqualFold(value, n.value)
+	})
+}
+
+// Индексы всех пользователей, для которых выполняется условие
+func (ev *queryEval) scan(match func(u *UserServer) bool) []int {
+	var result []int
+	for i := range ev.ds.users {
+		if match(&ev.ds.users[i]) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// Лексемы запроса
+const (
+	tokEOF = iota
+	tokWord
+	tokPhrase
+	tokField
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type queryToken struct {
+	kind int
+	text string
+	pos  int // смещение в байтах
+}
+
+// Разбор запроса вида
+//
+//	name:boyd AND (age:>30 OR gender:female) NOT "nisi mollit" Hil*
+//
+// Слова без операторов объединяются через AND, так что обычный текст
+// ищется как раньше. AND, OR и NOT без операнда, непарные скобки и кавычки
+// считаются текстом; ошибкой остаётся только неверное значение поля.
+// Пустой запрос возвращает nil
+func parseQuery(query string) (queryNode, error) {
+	p := &queryParser{input: query}
+	p.lex()
+	if p.peek().kind == tokEOF {
+		return nil, nil
+	}
+	// Скобки парные, поэтому разбор всегда доходит до конца запроса
+	return p.parseOr()
+}
+
+type queryParser struct {
+	input  string
+	tokens []queryToken
+	next   int
+}
+
+func (p *queryParser) errorAt(offset int, msg string) error {
+	return &QuerySyntaxError{Pos: utf8.RuneCountInString(p.input[:offset]) + 1, Msg: msg}
+}
+
+func (p *queryParser) lex() {
+	s := p.input
+	operators := queryOperators(s)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case operators[i] && r == '(':
+			p.tokens = append(p.tokens, queryToken{tokLParen, "(", i})
+			i++
+		case operators[i] && r == ')':
+			p.tokens = append(p.tokens, queryToken{tokRParen, ")", i})
+			i++
+		case operators[i]:
+			end := i + 1 + strings.IndexByte(s[i+1:], '"')
+			p.tokens = append(p.tokens, queryToken{tokPhrase, s[i+1 : end], i})
+			i = end + 1
+		default:
+			// Слово продолжается до пробела, оператора или двоеточия после
+			// известного поля; остальные двоеточия, скобки и кавычки - текст
+			end := i
+			for end < len(s) {
+				r, size := utf8.DecodeRuneInString(s[end:])
+				if unicode.IsSpace(r) || operators[end] {
+					break
+				}
+				if _, ok := queryFields[strings.ToLower(s[i:end])]; ok && r == ':' {
+					break
+				}
+				end += size
+			}
+			word := s[i:end]
+			switch {
+			case end < len(s) && s[end] == ':':
+				p.tokens = append(p.tokens, queryToken{tokField, word, i})
+				end++
+			case word == "AND":
+				p.tokens = append(p.tokens, queryToken{tokAnd, word, i})
+			case word == "OR":
+				p.tokens = append(p.tokens, queryToken{tokOr, word, i})
+			case word == "NOT":
+				p.tokens = append(p.tokens, queryToken{tokNot, word, i})
+			default:
+				p.tokens = append(p.tokens, queryToken{tokWord, word, i})
+			}
+			i = end
+		}
+	}
+	p.tokens = append(p.tokens, queryToken{tokEOF, "end of query", len(s)})
+}
+
+// Позиции кавычек и скобок, которые работают как операторы. Кавычки
+// разбиваются на пары слева направо, скобки учитываются только парные,
+// непустые и вне фраз. Остальные остаются частью текста, чтобы обычный
+// текст вроде a(b, () или 12" искался, а не отклонялся
+func queryOperators(s string) map[int]bool {
+	operators := make(map[int]bool)
+	var quotes []int
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			quotes = append(quotes, i)
+		}
+	}
+	inPhrase := make(map[int]bool)
+	for k := 0; k+1 < len(quotes); k += 2 {
+		operators[quotes[k]], operators[quotes[k+1]] = true, true
+		for i := quotes[k]; i <= quotes[k+1]; i++ {
+			inPhrase[i] = true
+		}
+	}
+
+	var open []int
+	for i := 0; i < len(s); i++ {
+		switch {
+		case inPhrase[i]:
+		case s[i] == '(':
+			open = append(open, i)
+		case s[i] == ')' && len(open) > 0:
+			if start := open[len(open)-1]; strings.TrimSpace(s[start+1:i]) != "" {
+				operators[start], operators[i] = true, true
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	return operators
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.next]
+}
+
+// Есть ли после лексемы с индексом n операнд для оператора. Операторы
+// на его месте станут словами в parsePrimary
+func (p *queryParser) operandAfter(n int) bool {
+	kind := p.tokens[n+1].kind
+	return kind != tokEOF && kind != tokRParen
+}
+
+func (p *queryParser) advance() queryToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr && p.operandAfter(p.next) {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch kind := p.peek().kind; {
+		case kind == tokAnd && p.operandAfter(p.next):
+			p.advance()
+		case kind == tokWord, kind == tokPhrase, kind == tokField, kind == tokLParen, kind == tokNot,
+			kind == tokAnd, kind == tokOr && !p.operandAfter(p.next):
+			// Соседние условия без оператора - неявный AND; AND и OR без
+			// правого операнда - слова
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.peek().kind == tokNot && p.operandAfter(p.next) {
+		p.advance()
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		// Парная скобка: внутри непустых скобок разбор останавливается на ней
+		p.advance()
+		return node, nil
+	case tokField:
+		return p.parseFieldValue(tok)
+	}
+	// Слово, фраза или оператор без операнда
+	return &textNode{text: tok.text, prefix: strings.HasSuffix(tok.text, "*")}, nil
+}
+
+func (p *queryParser) parseFieldValue(field queryToken) (queryNode, error) {
+	name := strings.ToLower(field.text)
+	kind := queryFields[name]
+
+	value := p.advance()
+	if value.kind != tokWord && value.kind != tokPhrase {
+		return nil, p.errorAt(value.pos, fmt.Sprintf("expected value for %s but found %q", field.text, value.text))
+	}
+
+	switch kind {
+	case queryFieldText:
+		return &textNode{
+			field:  name,
+			text:   value.text,
+			prefix: strings.HasSuffix(value.text, "*"),
+		}, nil
+	case queryFieldNumber:
+		op, number := splitComparison(value.text)
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return nil, p.errorAt(value.pos, fmt.Sprintf("expected number for %s but found %q", field.text, value.text))
+		}
+		return &numberNode{field: name, op: op, value: n}, nil
+	case queryFieldBool:
+		b, err := strconv.ParseBool(value.text)
+		if err != nil {
+			return nil, p.errorAt(value.pos, fmt.Sprintf("expected true or false for %s but found %q", field.text, value.text))
+		}
+		return &keywordNode{field: name, value: strconv.FormatBool(b)}, nil
+	}
+	return &keywordNode{field: name, value: value.text}, nil
+}
+
+// Отделение оператора сравнения от числа: ">=30" -> ">=", "30"
+func splitComparison(value string) (op, number string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "=", value
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+// Сортировка по релевантности запросу; без направления или с OrderByAsIs -
+// от лучших совпадений к худшим
+const OrderFieldRelevance = "Relevance"
+
+// Параметры BM25F: насыщение частоты слова, влияние длины поля
+// и вес совпадений в Name относительно About
+const (
+	bm25K1    = 1.2
+	bm25B     = 0.75
+	nameBoost = 3.0
+)
+
+// Слово запроса, участвующее в оценке, с заранее посчитанным IDF
+type scoredTerm struct {
+	token  string
+	prefix bool
+	idf    float64
+}
+
+// Слова из положительных (не под NOT) текстовых условий запроса
+func collectTextNodes(node queryNode, nodes []*textNode) []*textNode {
+	switch n := node.(type) {
+	case *andNode:
+		nodes = collectTextNodes(n.right, collectTextNodes(n.left, nodes))
+	case *orNode:
+		nodes = collectTextNodes(n.right, collectTextNodes(n.left, nodes))
+	case *textNode:
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// Слова для оценки: из разобранного запроса или, в режиме подстроки, из самого текста
+func (idx *invertedIndex) relevanceTerms(expr queryNode, query string) []scoredTerm {
+	var clauses []textClause
+	if expr != nil {
+		for _, node := range collectTextNodes(expr, nil) {
+			clauses = append(clauses, textClause{tokens: tokenize(idx.transform(node.text)), prefix: node.prefix})
+		}
+	} else {
+		clauses = append(clauses, textClause{tokens: tokenize(idx.transform(query))})
+	}
+
+	var terms []scoredTerm
+	for _, clause := range clauses {
+		for n, token := range clause.tokens {
+			prefix := clause.prefix && n == len(clause.tokens)-1
+			df := len(idx.postings[token])
+			if prefix {
+				df = len(idx.prefixPostings(token))
+			}
+			terms = append(terms, scoredTerm{token: token, prefix: prefix, idf: bm25IDF(len(idx.name), df)})
+		}
+	}
+	return terms
+}
+
+func bm25IDF(total, df int) float64 {
+	return math.Log(1 + (float64(total)-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// Оценка BM25F пользователя i по словам запроса
+func (idx *invertedIndex) score(i int, terms []scoredTerm) float64 {
+	nameNorm := 1 - bm25B + bm25B*float64(len(idx.name[i]))/math.Max(idx.avgName, 1)
+	aboutNorm := 1 - bm25B + bm25B*float64(len(idx.about[i]))/math.Max(idx.avgAbout, 1)
+
+	var score float64
+	for _, term := range terms {
+		tf := nameBoost*float64(countTerm(idx.name[i], term))/nameNorm +
+			float64(countTerm(idx.about[i], term))/aboutNorm
+		score += term.idf * tf * (bm25K1 + 1) / (tf + bm25K1)
+	}
+	return score
+}
+
+func countTerm(tokens []string, term scoredTerm) int {
+	count := 0
+	for _, token := range tokens {
+		if token == term.token || term.prefix && strings.HasPrefix(token, term.token) {
+			count++
+		}
+	}
+	return count
+}
+
+// Оценки найденных пользователей в порядке matched
+func (idx *invertedIndex) scoreAll(matched []int, terms []scoredTerm) []float64 {
+	scores := make([]float64, len(matched))
+	for k, i := range matched {
+		scores[k] = idx.score(i, terms)
+	}
+	return scores
+}
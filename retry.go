@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Источник времени клиента; в тестах подменяется, чтобы не ждать по-настоящему
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Политика повторов FindUsers. Повторяются только сбои, после которых
+// запрос имеет смысл отправить снова: таймауты, сетевые ошибки, ответы 5xx
+// и 429 с Retry-After
+type RetryPolicy struct {
+	// MaxAttempts - число попыток вместе с первой; 1 и меньше - без повторов
+	MaxAttempts int
+	// BaseDelay - пауза перед второй попыткой, дальше она удваивается
+	// до MaxDelay. Если сервер просит в Retry-After ждать дольше MaxDelay,
+	// запрос не повторяется, а ошибка возвращается с RetryAfter.
+	// По умолчанию 100 мс и 5 с
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Jitter - доля паузы, на которую она случайно сокращается, чтобы клиенты
+	// не повторяли запросы одновременно: 0 - без разброса, 1 - от нуля до полной
+	Jitter float64
+	// Rand возвращает случайное число из [0, 1); по умолчанию math/rand/v2
+	Rand func() float64
+	// OnAttempt вызывается после каждой неудачной попытки
+	OnAttempt func(RetryAttempt)
+}
+
+// Сведения о неудачной попытке
+type RetryAttempt struct {
+	Attempt int           // номер попытки, с 1
+	Err     error         // её ошибка
+	Delay   time.Duration // пауза перед следующей попыткой
+	Retry   bool          // будет ли следующая попытка
+}
+
+// WithRetry включает повторы неудачных запросов
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) { o.retry = &policy }
+}
+
+// WithClock подменяет источник времени для пауз между повторами и охлаждения
+// автомата защиты
+func WithClock(clock Clock) ClientOption {
+	return func(o *clientOptions) { o.clock = clock }
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 100 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 5 * time.Second
+	}
+	if p.Rand == nil {
+		p.Rand = rand.Float64
+	}
+	return p
+}
+
+// Пауза перед попыткой attempt+1: экспоненциальная с разбросом, либо
+// Retry-After из ответа сервера
+func (p *RetryPolicy) delay(attempt int, err error) time.Duration {
+	var searchErr *SearchError
+	if errors.As(err, &searchErr) && searchErr.RetryAfter > 0 {
+		return searchErr.RetryAfter
+	}
+	backoff := p.MaxDelay
+	if shift := attempt - 1; shift < 32 && p.BaseDelay<<shift < p.MaxDelay {
+		backoff = p.BaseDelay << shift
+	}
+	return backoff - time.Duration(p.Jitter*p.Rand()*float64(backoff))
+}
+
+// Стоит ли повторять запрос после err. Отмена и срок ctx вызывающего
+// не повторяются никогда
+func isRetryable(ctx context.Context, err error) bool {
+	if !isBackendFailure(ctx, err) {
+		return false
+	}
+	var searchErr *SearchError
+	if errors.As(err, &searchErr) && searchErr.StatusCode == http.StatusTooManyRequests {
+		return searchErr.RetryAfter > 0
+	}
+	return true
+}
+
+// Выполнение call с повторами по политике клиента. Пауза, которая длиннее
+// MaxDelay или не укладывается в срок ctx, не начинается: возвращается
+// последняя ошибка
+func (srv *SearchClient) withRetry(ctx context.Context, call func() (*SearchResponse, error)) (*SearchResponse, error) {
+	if srv.retry == nil {
+		return call()
+	}
+	policy := srv.retry.withDefaults()
+	clock := srv.clockOrDefault()
+	for attempt := 1; ; attempt++ {
+		resp, err := call()
+		if err == nil {
+			return resp, nil
+		}
+
+		info := RetryAttempt{Attempt: attempt, Err: err}
+		if attempt < policy.MaxAttempts && isRetryable(ctx, err) {
+			info.Delay = policy.delay(attempt, err)
+			deadline, ok := ctx.Deadline()
+			info.Retry = info.Delay <= policy.MaxDelay && (!ok || clock.Now().Add(info.Delay).Before(deadline))
+		}
+		if policy.OnAttempt != nil {
+			policy.OnAttempt(info)
+		}
+		if !info.Retry {
+			return nil, err
+		}
+
+		select {
+		case <-clock.After(info.Delay):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+func (srv *SearchClient) clockOrDefault() Clock {
+	if srv.clock == nil {
+		return realClock{}
+	}
+	return srv.clock
+}
+
+// Значение Retry-After: число секунд или HTTP-дата
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -1,27 +1,39 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
 	"encoding/json"
-	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const OrderFieldName = "Name"
 
-var datasetFilePath = "dataset.xml"
-
 type UserXML struct {
-	ID        int    `xml:"id"`
-	FirstName string `xml:"first_name"`
-	LastName  string `xml:"last_name"`
-	Age       int    `xml:"age"`
-	About     string `xml:"about"`
-	Gender    string `xml:"gender"`
+	ID            int    `xml:"id"`
+	GUID          string `xml:"guid"`
+	IsActive      bool   `xml:"isActive"`
+	Balance       string `xml:"balance"`
+	Picture       string `xml:"picture"`
+	Age           int    `xml:"age"`
+	EyeColor      string `xml:"eyeColor"`
+	FirstName     string `xml:"first_name"`
+	LastName      string `xml:"last_name"`
+	Gender        string `xml:"gender"`
+	Company       string `xml:"company"`
+	Email         string `xml:"email"`
+	Phone         string `xml:"phone"`
+	Address       string `xml:"address"`
+	About         string `xml:"about"`
+	Registered    string `xml:"registered"`
+	FavoriteFruit string `xml:"favoriteFruit"`
 }
 
 type UsersXML struct {
@@ -29,44 +41,81 @@ type UsersXML struct {
 }
 
 type UserServer struct {
-	ID     int
-	Name   string
-	Age    int
-	About  string
-	Gender string
+	ID            int
+	Name          string
+	Age           int
+	About         string
+	Gender        string
+	GUID          string
+	IsActive      bool
+	Balance       int64 // в центах
+	Picture       string
+	EyeColor      string
+	Company       string
+	Email         string
+	Phone         string
+	Address       string
+	Registered    time.Time
+	FavoriteFruit string
+	Score         float64 `json:",omitempty"` // релевантность при order_field=Relevance
 }
 
-var users []UserServer
-
-// Централизованная обработка ошибок
+// Централизованная обработка ошибок: JSON с кодом ошибки, параметром,
+// в котором она найдена, и текстом для человека
 func handleError(w http.ResponseWriter, err error, statusCode int) {
-	http.Error(w, err.Error(), statusCode)
+	writeJSONResponse(w, statusCode, errorResponse(err, statusCode))
 }
 
-// Загрузка данных из XML
-func loadData() error {
-	xmlFile, err := os.Open(datasetFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to open dataset file: %w", err)
-	}
-	defer xmlFile.Close()
+// Ошибка в значении параметра запроса
+type paramError struct {
+	param string
+	msg   string
+}
 
-	var data UsersXML
-	if err := xml.NewDecoder(xmlFile).Decode(&data); err != nil {
-		return fmt.Errorf("failed to decode XML: %w", err)
-	}
+func (e *paramError) Error() string {
+	return e.msg
+}
+
+func badParam(param, format string, args ...interface{}) error {
+	return &paramError{param: param, msg: fmt.Sprintf(format, args...)}
+}
+
+// Коды ошибок отдельных параметров; для остальных - ErrorBadParam
+var paramErrorCodes = map[string]string{
+	"limit":       ErrorBadLimit,
+	"offset":      ErrorBadOffset,
+	"order_field": ErrorBadOrderField,
+	"order_by":    ErrorBadOrderBy,
+	"query":       ErrorBadQuery,
+	"cursor":      ErrorBadCursor,
+}
+
+// Коды ошибок, не связанных с конкретным параметром
+var statusErrorCodes = map[int]string{
+	http.StatusBadRequest:          ErrorBadParam,
+	http.StatusUnauthorized:        ErrorUnauthorized,
+	http.StatusInternalServerError: ErrorInternal,
+	http.StatusServiceUnavailable:  ErrorCanceled,
+}
 
-	users = make([]UserServer, len(data.Users))
-	for i, u := range data.Users {
-		users[i] = UserServer{
-			ID:     u.ID,
-			Name:   u.FirstName + " " + u.LastName,
-			Age:    u.Age,
-			About:  u.About,
-			Gender: u.Gender,
+func errorResponse(err error, statusCode int) SearchErrorResponse {
+	resp := SearchErrorResponse{Error: statusErrorCodes[statusCode], Message: err.Error()}
+	var pe *paramError
+	var syntaxErr *QuerySyntaxError
+	switch {
+	case errors.As(err, &pe):
+		resp.Param = pe.param
+		if code, ok := paramErrorCodes[pe.param]; ok {
+			resp.Error = code
 		}
+	case errors.As(err, &syntaxErr):
+		resp.Error, resp.Param = ErrorBadQuery, "query"
+	case errors.Is(err, errStaleCursor):
+		resp.Error, resp.Param = ErrorStaleCursor, "cursor"
+	case errors.Is(err, errInvalidCursor):
+		resp.Error, resp.Param = ErrorBadCursor, "cursor"
 	}
-	return nil
+	return resp
 }
 
 // Универсальная функция для валидации параметров
@@ -77,55 +126,248 @@ func validateIntParam(value string, defaultVal int) (int, error) {
 	return strconv.Atoi(value)
 }
 
+// Разобранные и проверенные параметры запроса
+type searchParams struct {
+	limit    int
+	offset   int
+	query    SearchQuery
+	fields   []*userField
+	envelope bool   // ответ в конверте с метаданными страницы
+	cursor   string // курсор вместо offset
+	// последняя строка полной страницы нужна клиенту только для проверки
+	// наличия следующей, курсор ставится перед ней
+	lookahead bool
+}
+
 // Валидация и обработка параметров
-func validateParams(r *http.Request) (limit, offset int, orderField string, orderBy int, err error) {
-	if limit, err = validateIntParam(r.FormValue("limit"), 10); err != nil {
+func validateParams(r *http.Request) (params searchParams, err error) {
+	if params.limit, err = validateIntParam(r.FormValue("limit"), 10); err != nil || params.limit < 0 {
+		err = badParam("limit", "invalid limit: %s", r.FormValue("limit"))
+		return
+	}
+	if params.offset, err = validateIntParam(r.FormValue("offset"), 0); err != nil || params.offset < 0 {
+		err = badParam("offset", "invalid offset: %s", r.FormValue("offset"))
+		return
+	}
+	params.query.Query = r.FormValue("query")
+	params.query.Match = r.FormValue("match")
+	if params.query.Match != "" && !isMatchMode(params.query.Match) {
+		err = badParam("match", "invalid match: %s", params.query.Match)
+		return
+	}
+	if locale := r.FormValue("locale"); locale != "" {
+		var ok bool
+		if params.query.Locale, ok = parseLocale(locale); !ok {
+			err = badParam("locale", "invalid locale: %s", locale)
+			return
+		}
+	}
+	params.query.QueryMode = r.FormValue("query_mode")
+	if m := params.query.QueryMode; m != "" && m != QueryModeIndex && m != QueryModeSubstring {
+		err = badParam("query_mode", "invalid query_mode: %s", m)
+		return
+	}
+	params.query.OrderBy, err = validateIntParam(r.FormValue("order_by"), OrderByAsIs)
+	if o := params.query.OrderBy; err != nil || o != OrderByAsc && o != OrderByDesc && o != OrderByAsIs {
+		err = badParam("order_by", "invalid order_by: %s", r.FormValue("order_by"))
+		return
+	}
+	params.query.OrderField = r.FormValue("order_field")
+	switch f := params.query.OrderField; {
+	case f == "":
+		params.query.OrderField = OrderFieldName
+	case strings.ContainsAny(f, ",:"):
+		// Список ключей "Age:desc,Name:asc"
+		if params.query.Sort, err = parseSortKeys(f, params.query.OrderBy); err != nil {
+			return
+		}
+		params.query.OrderField = params.query.Sort[0].Field
+	case !isSortableField(f):
+		err = badParam("order_field", "invalid order_field: %s", f)
+		return
+	}
+	if params.query.Filter, err = validateFilter(r); err != nil {
+		return
+	}
+	if params.fields, err = parseFields(r.FormValue("fields")); err != nil {
 		return
 	}
-	if offset, err = validateIntParam(r.FormValue("offset"), 0); err != nil {
+	if params.envelope, err = validateBoolOption(r, "envelope", HeaderSearchEnvelope); err != nil {
 		return
 	}
-	orderField = r.FormValue("order_field")
-	if orderField == "" {
-		orderField = OrderFieldName
-	} else if orderField != "Id" && orderField != "Age" && orderField != OrderFieldName {
-		err = fmt.Errorf("invalid order_field: %s", orderField)
+	if params.lookahead, err = validateBoolOption(r, "lookahead", HeaderSearchLookahead); err != nil {
 		return
 	}
-	orderBy, err = validateIntParam(r.FormValue("order_by"), 0)
+	params.cursor = r.FormValue("cursor")
+	if params.cursor != "" && params.offset != 0 {
+		err = badParam("offset", "invalid offset: cursor and offset are mutually exclusive")
+	}
 	return
 }
 
-// Фильтрация и сортировка пользователей
-func filterAndSortUsers(users []UserServer, query, orderField string, orderBy int) []UserServer {
-	filtered := make([]UserServer, 0)
-	for _, user := range users {
-		if query == "" || strings.Contains(user.Name, query) || strings.Contains(user.About, query) {
-			filtered = append(filtered, user)
+// Флаг из параметра name или, если его нет, из заголовка header
+func validateBoolOption(r *http.Request, name, header string) (bool, error) {
+	value := r.FormValue(name)
+	if value == "" {
+		value = r.Header.Get(header)
+	}
+	if value == "" {
+		return false, nil
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, badParam(name, "invalid %s: %s", name, value)
+	}
+	return flag, nil
+}
+
+// Валидация структурных фильтров
+func validateFilter(r *http.Request) (filter UserFilter, err error) {
+	if filter.AgeMin, err = validateOptionalIntParam(r, "age_min"); err != nil {
+		return
+	}
+	if filter.AgeMax, err = validateOptionalIntParam(r, "age_max"); err != nil {
+		return
+	}
+	if filter.AgeMin != nil && filter.AgeMax != nil && *filter.AgeMin > *filter.AgeMax {
+		err = badParam("age_min", "invalid age range: age_min %d > age_max %d", *filter.AgeMin, *filter.AgeMax)
+		return
+	}
+	if value := r.FormValue("is_active"); value != "" {
+		isActive, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			err = badParam("is_active", "invalid is_active: %s", value)
+			return
 		}
+		filter.IsActive = &isActive
 	}
+	filter.Gender = strings.ToLower(r.FormValue("gender"))
+	if filter.Gender != "" && filter.Gender != "male" && filter.Gender != "female" {
+		err = badParam("gender", "invalid gender: %s", r.FormValue("gender"))
+		return
+	}
+	filter.EyeColor = r.FormValue("eye_color")
+	filter.Company = r.FormValue("company")
+	filter.FavoriteFruit = r.FormValue("favorite_fruit")
+	return
+}
 
-	sort.Slice(filtered, func(i, j int) bool {
-		switch orderField {
-		case "Id":
-			if orderBy == 1 {
-				return filtered[i].ID < filtered[j].ID
-			}
-			return filtered[i].ID > filtered[j].ID
-		case "Age":
-			if orderBy == 1 {
-				return filtered[i].Age < filtered[j].Age
-			}
-			return filtered[i].Age > filtered[j].Age
-		default:
-			if orderBy == 1 {
-				return filtered[i].Name < filtered[j].Name
-			}
-			return filtered[i].Name > filtered[j].Name
+// Необязательный неотрицательный целый параметр; nil, если не передан
+func validateOptionalIntParam(r *http.Request, name string) (*int, error) {
+	value := r.FormValue(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return nil, badParam(name, "invalid %s: %s", name, value)
+	}
+	return &n, nil
+}
+
+// Фильтрация и сортировка пользователей по готовым представлениям снимка.
+// При отмене ctx работа прерывается с ошибкой ctx.Err()
+func filterAndSortUsers(ctx context.Context, ds *dataset, q SearchQuery) ([]UserServer, error) {
+	match := q.Match
+	if match == "" {
+		match = MatchCaseInsensitive
+	}
+	idx := ds.index[match]
+
+	// Найденные пользователи выбираются в порядке первого ключа, остальные
+	// ключи и релевантность досортировываются после фильтрации
+	keys := q.sortKeys()
+	relevance := q.OrderField == OrderFieldRelevance
+	// Представления построены в общем порядке; строки по правилам языка
+	// досортировываются вместе с остальными ключами
+	coll := collations[q.Locale]
+	resort := len(keys) > 1
+	for _, k := range keys {
+		relevance = relevance || k.Field == OrderFieldRelevance
+		resort = resort || coll != nil && k.Field != OrderFieldRelevance && sortFieldsByName[k.Field].text != nil
+	}
+	key := newViewKey("", OrderByAsIs)
+	if len(keys) > 0 {
+		key = newViewKey("Id", OrderByAsc)
+		if keys[0].Field != OrderFieldRelevance {
+			key = newViewKey(keys[0].Field, keys[0].Order)
 		}
-	})
+	}
+	view := ds.sorted[key]
 
-	return filtered
+	var expr queryNode
+	substring := q.Query != "" && q.QueryMode == QueryModeSubstring
+	if q.Query != "" && !substring {
+		var err error
+		if expr, err = parseQuery(q.Query); err != nil {
+			return nil, err
+		}
+		if expr != nil {
+			view = orderCandidates(ds, key, expr.eval(&queryEval{ds: ds, idx: idx}))
+		}
+	}
+	query := matchTransforms[match](q.Query)
+	searchable := ds.searchable[match]
+
+	matched := make([]int, 0)
+	for n, i := range view {
+		if n%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !q.Filter.match(&ds.users[i]) {
+			continue
+		}
+		if substring && !strings.Contains(searchable[i].name, query) && !strings.Contains(searchable[i].about, query) {
+			continue
+		}
+		matched = append(matched, i)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var scores []float64
+	if relevance {
+		scores = idx.scoreAll(matched, idx.relevanceTerms(expr, query))
+	}
+	if resort || relevance && len(keys) > 0 {
+		sort.Stable(newKeyOrder(ds, keys, coll, matched, scores))
+	}
+
+	filtered := make([]UserServer, len(matched))
+	for k, i := range matched {
+		filtered[k] = ds.users[i]
+		if relevance {
+			filtered[k].Score = scores[k]
+		}
+	}
+	return filtered, nil
+}
+
+// Через сколько просмотренных пользователей проверяется отмена запроса
+const cancelCheckInterval = 4096
+
+// Кандидаты из индекса в порядке представления. Немногих кандидатов дешевле
+// отсортировать по позициям, а при большом их числе - пройти представление целиком
+func orderCandidates(ds *dataset, key viewKey, candidates []int) []int {
+	if len(candidates) < len(ds.users)/8 {
+		rank := ds.rank[key]
+		ordered := append([]int(nil), candidates...)
+		sort.Slice(ordered, func(a, b int) bool { return rank[ordered[a]] < rank[ordered[b]] })
+		return ordered
+	}
+
+	matched := make([]bool, len(ds.users))
+	for _, i := range candidates {
+		matched[i] = true
+	}
+	ordered := make([]int, 0, len(candidates))
+	for _, i := range ds.sorted[key] {
+		if matched[i] {
+			ordered = append(ordered, i)
+		}
+	}
+	return ordered
 }
 
 // Пагинация пользователей
@@ -141,10 +383,16 @@ func paginate(users []UserServer, limit, offset int) []UserServer {
 
 // Централизованная отправка JSON-ответа
 func writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		http.Error(w, "Failed to write response", http.StatusInternalServerError)
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(data); err != nil {
+		// Ответ об ошибке заранее известен и сериализуется всегда
+		buf.Reset()
+		json.NewEncoder(&buf).Encode(SearchErrorResponse{Error: ErrorInternal, Message: "Failed to write response"}) //nolint:errcheck
+		statusCode = http.StatusInternalServerError
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(buf.Bytes()) //nolint:errcheck
 }
 
 // Вспомогательная функция для выполнения действий с проверкой на ошибки
@@ -156,10 +404,66 @@ func executeWithErrorCheck(w http.ResponseWriter, fn func() error, errorMsg stri
 	return true
 }
 
-func SearchServer(w http.ResponseWriter, r *http.Request) {
+// Настройки обработчика поиска
+type SearchHandlerOptions struct {
+	// Authorize проверяет токен доступа; по умолчанию достаточно непустого
+	Authorize func(token string) bool
+	// DefaultMatch - режим сравнения, если клиент не передал match;
+	// по умолчанию MatchCaseInsensitive
+	DefaultMatch string
+	// DefaultQueryMode - режим поиска, если клиент не передал query_mode;
+	// по умолчанию QueryModeIndex
+	DefaultQueryMode string
+	// DefaultLocale - язык сортировки строк, если клиент не передал locale;
+	// по умолчанию общий порядок без правил языка
+	DefaultLocale string
+	// LegacyQuery возвращает прежнее поведение для клиентов, которые не
+	// передают match и query_mode: query ищется в Name и About как подстрока
+	// с учётом регистра. Заменяет DefaultMatch и DefaultQueryMode
+	LegacyQuery bool
+	// CursorSecret - ключ подписи курсоров. По умолчанию случайный, и курсоры
+	// действуют, пока жив обработчик; несколько экземпляров за балансировщиком
+	// должны использовать общий ключ
+	CursorSecret []byte
+}
+
+// HTTP-обработчик поиска пользователей. Все зависимости передаются
+// в конструкторе, поэтому в одном процессе может работать несколько
+// обработчиков с разными наборами данных
+type SearchHandler struct {
+	store UserStore
+	opts  SearchHandlerOptions
+}
+
+func NewSearchHandler(store UserStore, opts SearchHandlerOptions) *SearchHandler {
+	if opts.Authorize == nil {
+		opts.Authorize = func(token string) bool { return token != "" }
+	}
+	if opts.LegacyQuery {
+		opts.DefaultMatch, opts.DefaultQueryMode = MatchExact, QueryModeSubstring
+	}
+	if !isMatchMode(opts.DefaultMatch) {
+		opts.DefaultMatch = MatchCaseInsensitive
+	}
+	if opts.DefaultQueryMode != QueryModeSubstring {
+		opts.DefaultQueryMode = ""
+	}
+	if locale, ok := parseLocale(opts.DefaultLocale); ok {
+		opts.DefaultLocale = locale
+	} else {
+		opts.DefaultLocale = ""
+	}
+	if len(opts.CursorSecret) == 0 {
+		opts.CursorSecret = make([]byte, 32)
+		rand.Read(opts.CursorSecret) //nolint:errcheck
+	}
+	return &SearchHandler{store: store, opts: opts}
+}
+
+func (h *SearchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Обертка для проверки access token
 	if !executeWithErrorCheck(w, func() error {
-		if r.Header.Get("AccessToken") == "" {
+		if !h.opts.Authorize(r.Header.Get("AccessToken")) {
 			return fmt.Errorf("unauthorized")
 		}
 		return nil
@@ -168,20 +472,106 @@ func SearchServer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Валидация параметров запроса
-	limit, offset, orderField, orderBy, err := validateParams(r)
+	params, err := validateParams(r)
 	if err != nil {
 		handleError(w, err, http.StatusBadRequest)
 		return
 	}
+	if params.query.Match == "" {
+		params.query.Match = h.opts.DefaultMatch
+	}
+	if params.query.QueryMode == "" {
+		params.query.QueryMode = h.opts.DefaultQueryMode
+	}
+	if r.FormValue("locale") == "" {
+		params.query.Locale = h.opts.DefaultLocale
+	}
+
+	// Ответ не изменился, если не изменился снимок: поиск не нужен
+	if versioned, ok := h.store.(VersionedStore); ok && r.Header.Get("If-None-Match") != "" {
+		if version, err := versioned.Version(r.Context()); err == nil &&
+			writeNotModified(w, r, responseETag(version, r.URL.Query(), params), version) {
+			return
+		}
+	}
+
+	// Поиск по текущему снимку данных хранилища
+	result, err := h.store.Search(r.Context(), params.query)
+	if err != nil {
+		// Синтаксическая ошибка в query - ошибка клиента, остальное - сбой хранилища
+		var syntaxErr *QuerySyntaxError
+		if errors.As(err, &syntaxErr) {
+			handleError(w, err, http.StatusBadRequest)
+			return
+		}
+		// Клиент ушёл или истёк срок запроса: ответ уже никому не нужен
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			handleError(w, fmt.Errorf("Request canceled: %w", err), http.StatusServiceUnavailable)
+			return
+		}
+		handleError(w, fmt.Errorf("Failed to load data: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Курсор заменяет offset и действует только для той же версии снимка
+	if params.cursor != "" {
+		if params.offset, err = cursorOffset(h.opts.CursorSecret, params.cursor, params.query, result); err != nil {
+			handleError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
 
-	// Загрузка данных
-	if !executeWithErrorCheck(w, loadData, "Failed to load data", http.StatusInternalServerError) {
+	etag := responseETag(result.Version, r.URL.Query(), params)
+	if writeNotModified(w, r, etag, result.Version) {
 		return
 	}
 
-	// Основная логика фильтрации, сортировки и ответа
-	filteredUsers := filterAndSortUsers(users, r.FormValue("query"), orderField, orderBy)
-	paginatedUsers := paginate(filteredUsers, limit, offset)
+	paginatedUsers := paginate(result.Users, params.limit, params.offset)
+
+	setDatasetHeaders(w, result)
+	w.Header().Set("ETag", etag)
+	next := nextCursor(h.opts.CursorSecret, params.query, result, paginatedUsers, params.offset, params.limit, params.lookahead)
+	if next != "" {
+		w.Header().Set(HeaderNextCursor, next)
+	}
+	var users interface{} = paginatedUsers
+	if len(params.fields) > 0 {
+		users = projectUsers(paginatedUsers, params.fields)
+	}
+	if params.envelope {
+		envelope := newSearchEnvelope(users, len(result.Users), params.offset, params.limit, len(paginatedUsers), params.lookahead)
+		envelope.NextCursor = next
+		users = envelope
+	}
+	writeJSONResponse(w, http.StatusOK, users)
+}
+
+// Страница пользователей вместе с общим числом найденных
+type searchEnvelope struct {
+	Users      interface{} `json:"users"`
+	Total      int         `json:"total"`
+	Offset     int         `json:"offset"`
+	Limit      int         `json:"limit"`
+	NextOffset *int        `json:"next_offset"` // null на последней странице
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// При lookahead лишняя строка полной страницы, как и в nextCursor, не
+// входит ни в limit, ни в next_offset
+func newSearchEnvelope(users interface{}, total, offset, limit, count int, lookahead bool) searchEnvelope {
+	if lookahead {
+		limit--
+		count = min(count, limit)
+	}
+	envelope := searchEnvelope{Users: users, Total: total, Offset: offset, Limit: limit}
+	if next := offset + count; count > 0 && next < total {
+		envelope.NextOffset = &next
+	}
+	return envelope
+}
 
-	writeJSONResponse(w, http.StatusOK, paginatedUsers)
+// Заголовки, по которым клиент видит, какой снимок данных ответил
+func setDatasetHeaders(w http.ResponseWriter, result *SearchResult) {
+	w.Header().Set(HeaderDatasetVersion, result.Version)
+	w.Header().Set(HeaderDatasetLoadedAt, result.LoadedAt.UTC().Format(time.RFC3339))
 }
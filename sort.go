@@ -0,0 +1,228 @@
+package main
+
+import (
+	"cmp"
+	"strings"
+)
+
+// Разбор списка ключей сортировки вида "Age:desc,Name:asc". Ключ без
+// направления получает направление из order_by, а при OrderByAsIs -
+// направление по умолчанию для поля, см. defaultOrder
+func parseSortKeys(value string, orderBy int) ([]SortKey, error) {
+	var keys []SortKey
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		field, direction, hasDirection := strings.Cut(strings.TrimSpace(item), ":")
+		if !isSortableField(field) {
+			return nil, badParam("order_field", "invalid order_field: %s", item)
+		}
+		if seen[field] {
+			return nil, badParam("order_field", "invalid order_field: duplicate %s", field)
+		}
+		seen[field] = true
+
+		key := SortKey{Field: field, Order: orderBy}
+		if orderBy == OrderByAsIs {
+			key.Order = defaultOrder(field)
+		}
+		if hasDirection {
+			switch strings.ToLower(direction) {
+			case "asc":
+				key.Order = OrderByAsc
+			case "desc":
+				key.Order = OrderByDesc
+			default:
+				return nil, badParam("order_field", "invalid order_field: %s", item)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Поле, по которому можно сортировать. Числа и время сравниваются через
+// compare, строки - по ключам сопоставления из text. value - указатель на
+// поле пользователя, через него значение попадает в курсор и обратно
+type sortField struct {
+	name    string
+	compare func(a, b *UserServer) int
+	text    func(u *UserServer) string
+	value   func(u *UserServer) any
+}
+
+// Поля сортировки; для каждого строятся представления по возрастанию и убыванию
+var sortFields = []sortField{
+	{name: "Id", compare: func(a, b *UserServer) int { return cmp.Compare(a.ID, b.ID) }, value: func(u *UserServer) any { return &u.ID }},
+	{name: "Age", compare: func(a, b *UserServer) int { return cmp.Compare(a.Age, b.Age) }, value: func(u *UserServer) any { return &u.Age }},
+	{name: OrderFieldName, text: func(u *UserServer) string { return u.Name }, value: func(u *UserServer) any { return &u.Name }},
+	{name: "Balance", compare: func(a, b *UserServer) int { return cmp.Compare(a.Balance, b.Balance) }, value: func(u *UserServer) any { return &u.Balance }},
+	{name: "Registered", compare: func(a, b *UserServer) int { return a.Registered.Compare(b.Registered) }, value: func(u *UserServer) any { return &u.Registered }},
+	{name: "Company", text: func(u *UserServer) string { return u.Company }, value: func(u *UserServer) any { return &u.Company }},
+	{name: "Email", text: func(u *UserServer) string { return u.Email }, value: func(u *UserServer) any { return &u.Email }},
+}
+
+var sortFieldsByName = func() map[string]*sortField {
+	byName := make(map[string]*sortField, len(sortFields))
+	for i := range sortFields {
+		byName[sortFields[i].name] = &sortFields[i]
+	}
+	return byName
+}()
+
+func isSortableField(field string) bool {
+	_, ok := sortFieldsByName[field]
+	return ok || field == OrderFieldRelevance
+}
+
+// Ключ сопоставления строки в общем порядке: без учёта регистра и
+// диакритики, так что "émile" стоит рядом с "Emile", а не после "zoe".
+// Правила отдельных языков, например шведское "ä" после "z", - в collations
+func collationKey(s string) string {
+	return normalizeText(s)
+}
+
+// Сравнение строк по ключам сопоставления; при равных ключах - побайтово,
+// чтобы порядок оставался строгим
+func compareCollated(keyA, keyB, a, b string) int {
+	if c := strings.Compare(keyA, keyB); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// Сравнение пользователей по полю сортировки с правилами языка coll: -1, 0 или 1
+func compareUsersBy(field string, coll collation, a, b *UserServer) int {
+	f := sortFieldsByName[field]
+	if f.text == nil {
+		return f.compare(a, b)
+	}
+	va, vb := f.text(a), f.text(b)
+	return compareCollated(coll.key(va), coll.key(vb), va, vb)
+}
+
+// Указатель на значение ключа сортировки у пользователя
+func sortKeyValue(field string, u *UserServer) any {
+	if field == OrderFieldRelevance {
+		return &u.Score
+	}
+	return sortFieldsByName[field].value(u)
+}
+
+// Сравнение пользователей по списку ключей с учётом направлений и
+// с ID при равенстве всех ключей - тот же порядок, что у keyOrder
+func compareUsersByKeys(keys []SortKey, coll collation, a, b *UserServer) int {
+	for _, key := range keys {
+		var c int
+		if key.Field == OrderFieldRelevance {
+			c = cmp.Compare(a.Score, b.Score)
+		} else {
+			c = compareUsersBy(key.Field, coll, a, b)
+		}
+		if c != 0 {
+			return c * key.Order
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// Сравнение пользователей снимка по индексам с заранее посчитанными
+// ключами сопоставления
+func (ds *dataset) comparator(field string) func(i, j int) int {
+	f := sortFieldsByName[field]
+	if f.text == nil {
+		return func(i, j int) int { return f.compare(&ds.users[i], &ds.users[j]) }
+	}
+	keys := ds.collation[field]
+	return func(i, j int) int {
+		return compareCollated(keys[i], keys[j], f.text(&ds.users[i]), f.text(&ds.users[j]))
+	}
+}
+
+// Сравнение по правилам языка coll; ключи считаются только для
+// найденных пользователей matched
+func (ds *dataset) localeComparator(field string, coll collation, matched []int) func(i, j int) int {
+	f := sortFieldsByName[field]
+	keys := make(map[int]string, len(matched))
+	for _, i := range matched {
+		keys[i] = coll.key(f.text(&ds.users[i]))
+	}
+	return func(i, j int) int {
+		return compareCollated(keys[i], keys[j], f.text(&ds.users[i]), f.text(&ds.users[j]))
+	}
+}
+
+// Направление поля, когда оно не задано: релевантность - от лучших
+// совпадений к худшим, остальные поля - по возрастанию
+func defaultOrder(field string) int {
+	if field == OrderFieldRelevance {
+		return OrderByDesc
+	}
+	return OrderByAsc
+}
+
+// Ключи сортировки запроса: явный список или пара OrderField/OrderBy.
+// Пустой результат - порядок набора данных; с OrderByAsIs сортировка
+// остаётся только для Relevance, от лучших совпадений к худшим
+func (q *SearchQuery) sortKeys() []SortKey {
+	if len(q.Sort) > 0 {
+		return q.Sort
+	}
+	if q.OrderBy == OrderByAsIs && q.OrderField == OrderFieldRelevance {
+		return []SortKey{{Field: OrderFieldRelevance, Order: OrderByDesc}}
+	}
+	if q.OrderBy == OrderByAsIs {
+		return nil
+	}
+	return []SortKey{{Field: q.OrderField, Order: q.OrderBy}}
+}
+
+// Упорядочение найденных пользователей по нескольким ключам; scores идут
+// параллельно matched и нужны для ключа Relevance, coll - правила языка
+// для строковых полей. При равенстве всех ключей пользователи идут по
+// возрастанию ID
+type keyOrder struct {
+	ds      *dataset
+	keys    []SortKey
+	compare []func(i, j int) int // nil для Relevance
+	matched []int
+	scores  []float64
+}
+
+func newKeyOrder(ds *dataset, keys []SortKey, coll collation, matched []int, scores []float64) *keyOrder {
+	o := &keyOrder{ds: ds, keys: keys, compare: make([]func(i, j int) int, len(keys)), matched: matched, scores: scores}
+	for n, key := range keys {
+		switch {
+		case key.Field == OrderFieldRelevance:
+		case coll != nil && sortFieldsByName[key.Field].text != nil:
+			o.compare[n] = ds.localeComparator(key.Field, coll, matched)
+		default:
+			o.compare[n] = ds.comparator(key.Field)
+		}
+	}
+	return o
+}
+
+func (o *keyOrder) Len() int { return len(o.matched) }
+
+func (o *keyOrder) Less(a, b int) bool {
+	i, j := o.matched[a], o.matched[b]
+	for n, key := range o.keys {
+		var c int
+		if o.compare[n] == nil {
+			c = cmp.Compare(o.scores[a], o.scores[b])
+		} else {
+			c = o.compare[n](i, j)
+		}
+		if c != 0 {
+			return c*key.Order < 0
+		}
+	}
+	return o.ds.users[i].ID < o.ds.users[j].ID
+}
+
+func (o *keyOrder) Swap(a, b int) {
+	o.matched[a], o.matched[b] = o.matched[b], o.matched[a]
+	if o.scores != nil {
+		o.scores[a], o.scores[b] = o.scores[b], o.scores[a]
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// Источник пользователей для SearchServer
+type UserStore interface {
+	// List возвращает всех пользователей в порядке набора данных
+	List(ctx context.Context) ([]UserServer, error)
+	// Get возвращает пользователя по ID или ErrUserNotFound
+	Get(ctx context.Context, id int) (UserServer, error)
+	// Search возвращает всех подходящих под запрос пользователей в нужном порядке
+	Search(ctx context.Context, q SearchQuery) (*SearchResult, error)
+}
+
+// Параметры поиска без пагинации
+type SearchQuery struct {
+	Query      string
+	QueryMode  string // QueryModeIndex по умолчанию или QueryModeSubstring
+	Match      string // режим сравнения, по умолчанию MatchCaseInsensitive
+	Locale     string // язык сортировки строк, см. collations; пустой - общий порядок
+	OrderField string
+	OrderBy    int
+	Sort       []SortKey // если задан, заменяет OrderField и OrderBy
+	Filter     UserFilter
+}
+
+// Структурные фильтры; пустые значения не ограничивают выборку.
+// Строковые поля сравниваются целиком без учёта регистра
+type UserFilter struct {
+	AgeMin        *int
+	AgeMax        *int
+	Gender        string
+	IsActive      *bool
+	EyeColor      string
+	Company       string
+	FavoriteFruit string
+}
+
+func (f *UserFilter) match(u *UserServer) bool {
+	switch {
+	case f.AgeMin != nil && u.Age < *f.AgeMin,
+		f.AgeMax != nil && u.Age > *f.AgeMax,
+		f.IsActive != nil && u.IsActive != *f.IsActive,
+		!matchFilterString(f.Gender, u.Gender),
+		!matchFilterString(f.EyeColor, u.EyeColor),
+		!matchFilterString(f.Company, u.Company),
+		!matchFilterString(f.FavoriteFruit, u.FavoriteFruit):
+		return false
+	}
+	return true
+}
+
+func matchFilterString(filter, value string) bool {
+	return filter == "" || strings.EqualFold(filter, value)
+}
+
+// Результат поиска вместе с описанием снимка, из которого он получен
+type SearchResult struct {
+	Users    []UserServer
+	Version  string
+	LoadedAt time.Time
+}
+
+// Пользователи, переданные при создании; используется в тестах и там,
+// где данные уже лежат в памяти
+type MemoryStore struct {
+	ds *dataset
+}
+
+func NewMemoryStore(users []UserServer) *MemoryStore {
+	ds := newDataset(users, time.Now())
+	ds.version = memoryVersion(users)
+	return &MemoryStore{ds: ds}
+}
+
+// Версия набора в памяти - хеш его содержимого
+func memoryVersion(users []UserServer) string {
+	hash := fnv.New64a()
+	json.NewEncoder(hash).Encode(users) //nolint:errcheck
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (s *MemoryStore) List(ctx context.Context) ([]UserServer, error) {
+	return s.ds.list(), nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id int) (UserServer, error) {
+	return s.ds.get(id)
+}
+
+func (s *MemoryStore) Search(ctx context.Context, q SearchQuery) (*SearchResult, error) {
+	return s.ds.search(ctx, q)
+}
+
+// Копия списка, чтобы вызывающий не мог изменить снимок
+func (ds *dataset) list() []UserServer {
+	return append([]UserServer(nil), ds.users...)
+}
+
+func (ds *dataset) get(id int) (UserServer, error) {
+	i, ok := ds.byID[id]
+	if !ok {
+		return UserServer{}, fmt.Errorf("%w: %d", ErrUserNotFound, id)
+	}
+	return ds.users[i], nil
+}
+
+func (ds *dataset) search(ctx context.Context, q SearchQuery) (*SearchResult, error) {
+	users, err := filterAndSortUsers(ctx, ds, q)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResult{
+		Users:    users,
+		Version:  ds.version,
+		LoadedAt: ds.loadedAt,
+	}, nil
+}